@@ -0,0 +1,61 @@
+// Command apikey issues API keys from the command line, which is how the
+// first key for a new owner is created.
+//
+//	go run ./cmd/apikey -name "marketing"
+//	go run ./cmd/apikey -name "ci" -owner 6de45a29-f9bc-43e3-87f5-fe11dcbcf2fc
+//	go run ./cmd/apikey -name "partner" -plan pro
+//	go run ./cmd/apikey -name "trust-and-safety" -admin
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/mohan7-code/url-shortener/config"
+	"github.com/mohan7-code/url-shortener/database"
+	"github.com/mohan7-code/url-shortener/middleware"
+	service "github.com/mohan7-code/url-shortener/services"
+	"github.com/mohan7-code/url-shortener/utils/cache"
+	appctx "github.com/mohan7-code/url-shortener/utils/context"
+)
+
+func main() {
+	name := flag.String("name", "", "label for the key")
+	owner := flag.String("owner", "", "existing owner ID; a new owner is created when empty")
+	plan := flag.String("plan", "", "rate limit plan; the default plan when empty")
+	admin := flag.Bool("admin", false, "allow the key to review reports and moderate links")
+	flag.Parse()
+
+	ownerID := uuid.Nil
+	if *owner != "" {
+		parsed, err := uuid.Parse(*owner)
+		if err != nil {
+			log.Fatalf("Invalid owner ID: %v", err)
+		}
+		ownerID = parsed
+	}
+
+	cnf, err := config.LoadConfig(".env")
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	if err := database.Init(&database.Config{
+		URL:       cnf.DatabaseUrl,
+		MaxDBConn: 1,
+	}); err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	cache.SetRedis()
+
+	ctx := appctx.NewBackground(middleware.Logger())
+
+	key, rawKey, err := service.NewAPIKeyService().CreateKey(ctx, ownerID, *name, *plan, *admin)
+	if err != nil {
+		log.Fatalf("Failed to create api key: %v", err)
+	}
+
+	fmt.Printf("owner_id: %s\nkey_id:   %s\nplan:     %s\nadmin:    %t\napi_key:  %s\n", key.OwnerID, key.ID, key.Plan, key.IsAdmin, rawKey)
+}
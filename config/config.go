@@ -5,6 +5,8 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -16,6 +18,104 @@ type Config struct {
 	BaseShortURL string
 
 	RedisURL string
+
+	ExpirySweepInterval time.Duration
+	ExpiredRetention    time.Duration
+
+	IPHashSalt    string
+	CountryHeader string
+
+	// GeoIPPath is a MaxMind-format country database used to find the
+	// country of clients the COUNTRY_HEADER proxy header does not cover.
+	GeoIPPath string
+
+	ClickBufferSize    int
+	ClickFlushBatch    int
+	ClickFlushInterval time.Duration
+
+	CodeStrategy string
+	CodeLength   int
+	CodeSalt     string
+
+	// URL normalization steps applied before links are stored and deduplicated.
+	NormalizeLowercaseHost bool
+	NormalizeDefaultPort   bool
+	NormalizePunycode      bool
+	NormalizePath          bool
+	NormalizeSortQuery     bool
+	NormalizeStripTracking bool
+	TrackingParams         []string
+
+	// Destination screening: a blocklist file polled for changes, the
+	// domains of other URL shorteners, and whether hostnames are resolved
+	// to catch ones pointing at internal addresses.
+	BlocklistPath           string
+	BlocklistReloadInterval time.Duration
+	ShortenerDomains        []string
+	ScreenResolveDNS        bool
+
+	// ReportReviewThreshold is the number of open abuse reports at which
+	// admins are alerted, through ReportAlertWebhook when it is set.
+	ReportReviewThreshold int
+	ReportAlertWebhook    string
+
+	// Wrong passwords allowed per protected link and client IP within
+	// PasswordLockout before further attempts are refused until the window
+	// ends. PasswordMaxCodeAttempts is the ceiling across all clients.
+	PasswordMaxAttempts     int
+	PasswordMaxCodeAttempts int
+	PasswordLockout         time.Duration
+
+	// VariantCookieTTL is how long a visitor keeps seeing the same A/B variant.
+	VariantCookieTTL time.Duration
+
+	// PermanentRedirectMaxAge is how long browsers and proxies may cache a
+	// 301 or 308 redirect. Zero sends no-store for every redirect.
+	PermanentRedirectMaxAge time.Duration
+
+	// IdempotencyTTL is how long a response is replayed for retries that
+	// send the same Idempotency-Key.
+	IdempotencyTTL time.Duration
+
+	// IdempotencyMaxBody is the largest request body read to fingerprint a
+	// request that carries an Idempotency-Key.
+	IdempotencyMaxBody int64
+
+	// ShortenBatchLimit is the most links one /shorten/batch call may create.
+	ShortenBatchLimit int
+
+	// ImportMaxBytes is the largest file /urls/import accepts.
+	ImportMaxBytes int64
+
+	// RateLimits holds the quota for each route class, and RateLimitPlans
+	// the multiplier applied to it for keys on each plan.
+	RateLimits     map[string]RateLimit
+	RateLimitPlans map[string]float64
+}
+
+// Short code generation strategies, selected with CODE_STRATEGY.
+const (
+	CodeStrategyRandom   = "random"
+	CodeStrategySequence = "sequence"
+	CodeStrategyHashids  = "hashids"
+)
+
+// short_code is a VARCHAR(10)
+const maxCodeLength = 10
+
+// Route classes that carry their own rate limit.
+const (
+	RouteAPI      = "api"
+	RouteShorten  = "shorten"
+	RouteRedirect = "redirect"
+	RouteReport   = "report"
+)
+
+// RateLimit allows Requests per Period, written as "<requests>/<period>"
+// in the environment, e.g. "60/1m".
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
 }
 
 var AppConfig *Config
@@ -58,6 +158,177 @@ func LoadConfig(envFile string) (*Config, error) {
 
 	cfg.RedisURL = os.Getenv("REDIS_URL")
 
+	cfg.ExpirySweepInterval = getEnvDuration("EXPIRY_SWEEP_INTERVAL", 5*time.Minute)
+	cfg.ExpiredRetention = getEnvDuration("EXPIRED_RETENTION", 24*time.Hour)
+
+	// an unsalted hash of an IPv4 address is as good as the address itself
+	cfg.IPHashSalt = os.Getenv("IP_HASH_SALT")
+	if cfg.IPHashSalt == "" {
+		return nil, errors.New("missing environment variable IP_HASH_SALT")
+	}
+	cfg.CountryHeader = os.Getenv("COUNTRY_HEADER")
+	cfg.GeoIPPath = os.Getenv("GEOIP_DB_PATH")
+
+	cfg.ClickBufferSize = getEnvInt("CLICK_BUFFER_SIZE", 10000)
+	cfg.ClickFlushBatch = getEnvInt("CLICK_FLUSH_BATCH", 500)
+	cfg.ClickFlushInterval = getEnvDuration("CLICK_FLUSH_INTERVAL", 2*time.Second)
+
+	cfg.CodeStrategy = os.Getenv("CODE_STRATEGY")
+	switch cfg.CodeStrategy {
+	case CodeStrategyRandom, CodeStrategySequence, CodeStrategyHashids:
+	case "":
+		cfg.CodeStrategy = CodeStrategyRandom
+	default:
+		log.Printf("Invalid CODE_STRATEGY %q, using %s", cfg.CodeStrategy, CodeStrategyRandom)
+		cfg.CodeStrategy = CodeStrategyRandom
+	}
+	cfg.CodeLength = min(getEnvInt("CODE_LENGTH", 7), maxCodeLength)
+	// without a salt every install shares the same hashids mapping, so codes
+	// could be enumerated
+	cfg.CodeSalt = os.Getenv("CODE_SALT")
+	if cfg.CodeStrategy == CodeStrategyHashids && cfg.CodeSalt == "" {
+		return nil, errors.New("missing environment variable CODE_SALT, required by the hashids code strategy")
+	}
+
+	cfg.NormalizeLowercaseHost = getEnvBool("NORMALIZE_LOWERCASE_HOST", true)
+	cfg.NormalizeDefaultPort = getEnvBool("NORMALIZE_DEFAULT_PORT", true)
+	cfg.NormalizePunycode = getEnvBool("NORMALIZE_PUNYCODE", true)
+	cfg.NormalizePath = getEnvBool("NORMALIZE_PATH", true)
+	cfg.NormalizeSortQuery = getEnvBool("NORMALIZE_SORT_QUERY", false)
+	cfg.NormalizeStripTracking = getEnvBool("NORMALIZE_STRIP_TRACKING", false)
+	cfg.TrackingParams = getEnvList("TRACKING_PARAMS", []string{"utm_*", "gclid", "fbclid", "msclkid", "mc_cid", "mc_eid"})
+
+	cfg.BlocklistPath = os.Getenv("BLOCKLIST_PATH")
+	cfg.BlocklistReloadInterval = getEnvDuration("BLOCKLIST_RELOAD_INTERVAL", 30*time.Second)
+	cfg.ShortenerDomains = getEnvList("SHORTENER_DOMAINS", []string{
+		"bit.ly", "bitly.com", "tinyurl.com", "t.co", "goo.gl", "ow.ly", "is.gd", "v.gd", "buff.ly",
+		"rebrand.ly", "cutt.ly", "shorturl.at", "tiny.cc", "rb.gy", "t.ly", "s.id", "bl.ink", "short.io",
+	})
+	cfg.ScreenResolveDNS = getEnvBool("SCREEN_RESOLVE_DNS", true)
+
+	cfg.ReportReviewThreshold = getEnvInt("REPORT_REVIEW_THRESHOLD", 3)
+	cfg.ReportAlertWebhook = os.Getenv("REPORT_ALERT_WEBHOOK")
+
+	cfg.PasswordMaxAttempts = getEnvInt("PASSWORD_MAX_ATTEMPTS", 5)
+	cfg.PasswordMaxCodeAttempts = getEnvInt("PASSWORD_MAX_CODE_ATTEMPTS", 50)
+	cfg.PasswordLockout = getEnvDuration("PASSWORD_LOCKOUT", 15*time.Minute)
+
+	cfg.VariantCookieTTL = getEnvDuration("VARIANT_COOKIE_TTL", 30*24*time.Hour)
+
+	cfg.PermanentRedirectMaxAge = getEnvDuration("PERMANENT_REDIRECT_MAX_AGE", 24*time.Hour)
+
+	cfg.IdempotencyTTL = getEnvDuration("IDEMPOTENCY_TTL", 24*time.Hour)
+	cfg.IdempotencyMaxBody = int64(getEnvInt("IDEMPOTENCY_MAX_BODY", 1<<20))
+
+	cfg.ShortenBatchLimit = getEnvInt("SHORTEN_BATCH_LIMIT", 100)
+	cfg.ImportMaxBytes = int64(getEnvInt("IMPORT_MAX_BYTES", 10<<20))
+
+	cfg.RateLimits = map[string]RateLimit{
+		RouteAPI:      getEnvRateLimit("RATE_LIMIT_API", RateLimit{Requests: 60, Period: time.Minute}),
+		RouteShorten:  getEnvRateLimit("RATE_LIMIT_SHORTEN", RateLimit{Requests: 30, Period: time.Minute}),
+		RouteRedirect: getEnvRateLimit("RATE_LIMIT_REDIRECT", RateLimit{Requests: 1200, Period: time.Minute}),
+		RouteReport:   getEnvRateLimit("RATE_LIMIT_REPORT", RateLimit{Requests: 10, Period: time.Hour}),
+	}
+	cfg.RateLimitPlans = getEnvPlans("RATE_LIMIT_PLANS", map[string]float64{"free": 1, "pro": 10})
+
 	AppConfig = cfg
 	return cfg, nil
 }
+
+func getEnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Printf("Invalid value for %s, using %d", key, fallback)
+		return fallback
+	}
+	return n
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid duration for %s, using %s", key, fallback)
+		return fallback
+	}
+	return d
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid value for %s, using %t", key, fallback)
+		return fallback
+	}
+	return b
+}
+
+// getEnvList reads a comma separated list.
+func getEnvList(key string, fallback []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func getEnvRateLimit(key string, fallback RateLimit) RateLimit {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	requests, period, ok := strings.Cut(value, "/")
+	n, err := strconv.Atoi(requests)
+	if !ok || err != nil || n <= 0 {
+		log.Printf("Invalid rate limit for %s, using %d/%s", key, fallback.Requests, fallback.Period)
+		return fallback
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid rate limit for %s, using %d/%s", key, fallback.Requests, fallback.Period)
+		return fallback
+	}
+	return RateLimit{Requests: n, Period: d}
+}
+
+// getEnvPlans reads plan multipliers written as "free=1,pro=10".
+func getEnvPlans(key string, fallback map[string]float64) map[string]float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	plans := make(map[string]float64)
+	for _, entry := range strings.Split(value, ",") {
+		name, multiplier, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		m, err := strconv.ParseFloat(multiplier, 64)
+		if !ok || name == "" || err != nil || m <= 0 {
+			log.Printf("Invalid plans for %s, using defaults", key)
+			return fallback
+		}
+		plans[name] = m
+	}
+	return plans
+}
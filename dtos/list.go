@@ -2,6 +2,9 @@ package dtos
 
 import (
 	"time"
+
+	"github.com/google/uuid"
+	"github.com/mohan7-code/url-shortener/models"
 )
 
 type ListResponse struct {
@@ -10,13 +13,210 @@ type ListResponse struct {
 	Pages      int   `json:"pages"`
 }
 
+// CursorListResponse is one page of a cursor-paginated listing. NextCursor
+// and PrevCursor are set when there is a page in that direction.
+type CursorListResponse struct {
+	Data       any    `json:"data"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
 type Analytics struct {
 	ShortCode      string    `json:"short_code"`
 	OriginalURL    string    `json:"original_url"`
 	ClickCount     int64     `json:"click_count"`
 	LastAccessedAt time.Time `json:"last_accessed_at"`
+
+	From             time.Time       `json:"from"`
+	To               time.Time       `json:"to"`
+	Interval         string          `json:"interval"`
+	RangeClicks      int64           `json:"range_clicks"`
+	TimeSeries       []TimeBucket    `json:"time_series"`
+	TopReferrers     []BreakdownItem `json:"top_referrers"`
+	Browsers         []BreakdownItem `json:"browsers"`
+	OperatingSystems []BreakdownItem `json:"operating_systems"`
+	Countries        []BreakdownItem `json:"countries"`
+	Variants         []BreakdownItem `json:"variants"`
+}
+
+type AnalyticsQuery struct {
+	From     time.Time
+	To       time.Time
+	Interval string
+}
+
+type TimeBucket struct {
+	Start  time.Time `json:"start"`
+	Clicks int64     `json:"clicks"`
+}
+
+type BreakdownItem struct {
+	Value  string `json:"value"`
+	Clicks int64  `json:"clicks"`
 }
 type URLRequest struct {
-	OriginalURL string `json:"original_url"`
-	CustomAlias string `json:"custom_alias"`
+	OriginalURL string     `json:"original_url"`
+	CustomAlias string     `json:"custom_alias"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	MaxClicks   *int64     `json:"max_clicks"`
+	WorkspaceID *uuid.UUID `json:"workspace_id"`
+	Domain      string     `json:"domain"`
+
+	// Password makes visitors enter it before they are redirected.
+	Password string `json:"password"`
+
+	// Targeting sends visitors matching a rule to the rule's destination
+	// instead of OriginalURL.
+	Targeting models.TargetingRules `json:"targeting"`
+
+	// Variants splits visitors who match no targeting rule between
+	// weighted destinations.
+	Variants models.Variants `json:"variants"`
+
+	// Tags label the link for filtering.
+	Tags []string `json:"tags"`
+
+	// Title is a name for the link that searches match.
+	Title string `json:"title"`
+
+	// RedirectType is the status visitors are redirected with: 301, 302,
+	// 307 or 308. It defaults to 302.
+	RedirectType int `json:"redirect_type"`
+
+	// ReuseExisting returns the caller's newest live link to the same
+	// destination instead of creating one. It defaults to true.
+	ReuseExisting *bool `json:"reuse_existing"`
+}
+
+// BatchURLRequest creates several links in one call.
+type BatchURLRequest struct {
+	Items []URLRequest `json:"items"`
+}
+
+// ImportRow is one link of an import file: an NDJSON object, or a CSV
+// record with the same names as columns.
+type ImportRow struct {
+	Destination string     `json:"destination"`
+	Alias       string     `json:"alias"`
+	Tags        []string   `json:"tags"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// ExportedLink is one link of an export.
+type ExportedLink struct {
+	Alias       string     `json:"alias"`
+	ShortURL    string     `json:"short_url"`
+	Destination string     `json:"destination"`
+	Tags        []string   `json:"tags"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	ClickCount  int64      `json:"click_count"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// ListQuery selects a page of links. Cursor continues from the next_cursor
+// or prev_cursor of an earlier page listed with the same Sort and Order.
+type ListQuery struct {
+	Cursor      string
+	Limit       int
+	Sort        string
+	Order       string
+	WorkspaceID uuid.UUID
+
+	// Filters; zero values match every link.
+	Domain      string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	MinClicks   int64
+	Tag         string
+	Status      string
+}
+
+// SearchQuery searches the caller's links, or a workspace's links when
+// WorkspaceID is set.
+type SearchQuery struct {
+	Q           string
+	Limit       int
+	WorkspaceID uuid.UUID
+}
+
+// LinkRef identifies a link by its code and branded domain. An empty Domain
+// is the default short domain.
+type LinkRef struct {
+	Domain    string
+	ShortCode string
+}
+
+// UpdateURLRequest changes the fields that are set; at least one must be.
+// An empty Targeting, Variants or Tags list removes the link's rules,
+// variants or tags, and an empty Title removes its title.
+type UpdateURLRequest struct {
+	OriginalURL  string                 `json:"original_url"`
+	Targeting    *models.TargetingRules `json:"targeting"`
+	Variants     *models.Variants       `json:"variants"`
+	RedirectType *int                   `json:"redirect_type"`
+	Tags         *[]string              `json:"tags"`
+	Title        *string                `json:"title"`
+}
+
+// ClickInfo carries the request metadata recorded for every redirect.
+type ClickInfo struct {
+	Referrer       string
+	UserAgent      string
+	ClientIP       string
+	AcceptLanguage string
+	Country        string
+
+	// Variant is the visitor's sticky A/B variant when the request comes
+	// in, and the variant served once the redirect is resolved.
+	Variant string
+
+	// Uncounted resolves the redirect without recording a click, as for
+	// HEAD requests.
+	Uncounted bool
+}
+
+type APIKeyRequest struct {
+	Name string `json:"name"`
+}
+
+type WorkspaceRequest struct {
+	Name string `json:"name"`
+}
+
+type WorkspaceMemberRequest struct {
+	MemberID uuid.UUID `json:"member_id"`
+	Role     string    `json:"role"`
+}
+
+type DomainRequest struct {
+	Hostname string `json:"hostname"`
+}
+
+// QRQuery holds the rendering options of a link's QR code. Zero values
+// select the defaults.
+type QRQuery struct {
+	Format     string
+	Size       int
+	Margin     *int
+	Level      string
+	Foreground string
+	Background string
+}
+
+type ReportRequest struct {
+	Reason  string `json:"reason"`
+	Details string `json:"details"`
+}
+
+// ReportQuery selects open reports, or resolved ones when Resolved is set.
+type ReportQuery struct {
+	Resolved bool
+	Page     int
+	Limit    int
+}
+
+type ModerationRequest struct {
+	Status string `json:"status"`
+	Note   string `json:"note"`
 }
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mohan7-code/url-shortener/dtos"
+	service "github.com/mohan7-code/url-shortener/services"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+)
+
+func CreateAPIKey(c *context.Context) {
+	var req dtos.APIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	// new keys stay on the plan of the key that created them
+	key, rawKey, err := service.NewAPIKeyService().CreateKey(c, c.OwnerID, req.Name, c.Plan, false)
+	if err != nil {
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":         key.ID,
+		"name":       key.Name,
+		"owner_id":   key.OwnerID,
+		"plan":       key.Plan,
+		"key":        rawKey,
+		"created_at": key.CreatedAt,
+	})
+}
+
+func RevokeAPIKey(c *context.Context) {
+	if err := service.NewAPIKeyService().RevokeKey(c, c.Param("id")); err != nil {
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -1,17 +1,22 @@
 package handler
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mohan7-code/url-shortener/config"
 	"github.com/mohan7-code/url-shortener/dtos"
+	"github.com/mohan7-code/url-shortener/models"
 	service "github.com/mohan7-code/url-shortener/services"
 	context "github.com/mohan7-code/url-shortener/utils/context"
 )
 
+// variantCookie holds the A/B variant a visitor was served.
+const variantCookie = "variant"
+
 func CreateShortURL(c *context.Context) {
 	var req dtos.URLRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -22,54 +27,501 @@ func CreateShortURL(c *context.Context) {
 	s := service.NewURLService()
 	url, err := s.ShortenURL(c, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, createdBody(url))
+}
+
+// CreateShortURLBatch answers 200 with one result per item, in request
+// order. Each result carries the status the item would have got from
+// /shorten: 201 for a new link, 200 for a reused one, or an error status.
+func CreateShortURLBatch(c *context.Context) {
+	var req dtos.BatchURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
 		return
 	}
-	shortURL := fmt.Sprintf("%s/%s", config.AppConfig.BaseShortURL, url.ShortCode)
 
-	c.JSON(http.StatusCreated, gin.H{
-		"original_url": url.OriginalURL,
-		"short_url":    shortURL,
+	results, err := service.NewURLService().ShortenBatch(c, req.Items)
+	if err != nil {
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	failed := 0
+	items := make([]gin.H, len(results))
+	for i, result := range results {
+		if result.Err != nil {
+			failed++
+			items[i] = errorBody(result.Err)
+			items[i]["status"] = errorStatus(result.Err)
+		} else {
+			items[i] = createdBody(result.URL)
+			items[i]["status"] = http.StatusOK
+			if result.Created {
+				items[i]["status"] = http.StatusCreated
+			}
+		}
+		items[i]["index"] = i
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results":   items,
+		"succeeded": len(results) - failed,
+		"failed":    failed,
 	})
 }
 
+// createdBody describes a link returned by /shorten.
+func createdBody(url *models.URL) gin.H {
+	resp := gin.H{
+		"original_url":  url.OriginalURL,
+		"short_url":     service.ShortURL(url),
+		"redirect_type": url.RedirectType,
+	}
+	if url.ExpiresAt != nil {
+		resp["expires_at"] = url.ExpiresAt
+	}
+	if url.MaxClicks != nil {
+		resp["max_clicks"] = url.MaxClicks
+	}
+	if url.IsProtected() {
+		resp["password_protected"] = true
+	}
+	if len(url.Targeting) > 0 {
+		resp["targeting"] = url.Targeting
+	}
+	if len(url.Variants) > 0 {
+		resp["variants"] = url.Variants
+	}
+	if len(url.Tags) > 0 {
+		resp["tags"] = url.Tags
+	}
+	if url.Title != "" {
+		resp["title"] = url.Title
+	}
+	return resp
+}
+
 func RedirectURL(c *context.Context) {
 	shortCode := c.Param("shortCode")
 
 	s := service.NewURLService()
-	url, err := s.GetOriginalURL(c, shortCode)
+	click := clickInfo(c)
+	// link checkers and previews probe with HEAD; they get the same answer
+	// but are not counted as visitors
+	click.Uncounted = c.Request.Method == http.MethodHead
+	url, err := s.GetOriginalURL(c, c.Request.Host, shortCode, click)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		if errors.Is(err, service.ErrPasswordRequired) {
+			renderPasswordForm(c, http.StatusOK, "")
+			return
+		}
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	if !click.Uncounted {
+		setVariantCookie(c, click)
+	}
+
+	if url.Status == models.StatusUnderReview {
+		renderInterstitial(c, url)
 		return
 	}
 
-	c.Redirect(http.StatusFound, url.OriginalURL)
+	setRedirectCacheControl(c, url)
+	c.Redirect(url.RedirectType, url.OriginalURL)
 }
 
 func ListURLs(c *context.Context) {
-	page, _ := strconv.Atoi(c.Query("page"))
-
 	limit, _ := strconv.Atoi(c.Query("limit"))
 
+	query := &dtos.ListQuery{
+		Cursor: c.Query("cursor"),
+		Limit:  limit,
+		Sort:   c.Query("sort"),
+		Order:  c.Query("order"),
+		Domain: c.Query("domain"),
+		Tag:    c.Query("tag"),
+		Status: c.Query("status"),
+	}
+	workspaceID, ok := workspaceQuery(c)
+	if !ok {
+		return
+	}
+	query.WorkspaceID = workspaceID
+
+	if minClicks := c.Query("min_clicks"); minClicks != "" {
+		n, err := strconv.ParseInt(minClicks, 10, 64)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid min_clicks"})
+			return
+		}
+		query.MinClicks = n
+	}
+
+	var err error
+	if query.CreatedFrom, err = parseDateQuery(c.Query("created_from"), false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid created_from, use RFC 3339 or YYYY-MM-DD"})
+		return
+	}
+	if query.CreatedTo, err = parseDateQuery(c.Query("created_to"), true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid created_to, use RFC 3339 or YYYY-MM-DD"})
+		return
+	}
+
 	s := service.NewURLService()
-	resp, err := s.ListURLs(c, page, limit)
+	resp, err := s.ListURLs(c, query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), errorBody(err))
 		return
 	}
 
 	c.JSON(http.StatusOK, resp)
 }
 
+// SearchURLs answers GET /urls/search?q= with the caller's best matching
+// links, or a workspace's with ?workspace_id=.
+func SearchURLs(c *context.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	workspaceID, ok := workspaceQuery(c)
+	if !ok {
+		return
+	}
+	query := &dtos.SearchQuery{
+		Q:           c.Query("q"),
+		Limit:       limit,
+		WorkspaceID: workspaceID,
+	}
+
+	hits, err := service.NewURLService().SearchURLs(c, query)
+	if err != nil {
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": hits})
+}
+
 func GetAnalytics(ctx *context.Context) {
 
-	code := ctx.Param("code")
+	ref := linkRef(ctx)
 
-	data, err := service.NewURLService().GetAnalytics(ctx, code)
+	from, err := parseTimeQuery(ctx, "from")
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid from, expected RFC3339 timestamp"})
+		return
+	}
+	to, err := parseTimeQuery(ctx, "to")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid to, expected RFC3339 timestamp"})
+		return
+	}
+
+	query := &dtos.AnalyticsQuery{
+		From:     from,
+		To:       to,
+		Interval: ctx.Query("interval"),
+	}
+
+	data, err := service.NewURLService().GetAnalytics(ctx, ref, query)
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorBody(err))
 		return
 	}
 
 	ctx.JSON(http.StatusOK, data)
 }
+
+func UpdateURL(c *context.Context) {
+	ref := linkRef(c)
+
+	var req dtos.UpdateURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	url, err := service.NewURLService().UpdateURL(c, ref, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+	resp := gin.H{
+		"original_url":  url.OriginalURL,
+		"short_url":     service.ShortURL(url),
+		"redirect_type": url.RedirectType,
+	}
+	if len(url.Targeting) > 0 {
+		resp["targeting"] = url.Targeting
+	}
+	if len(url.Variants) > 0 {
+		resp["variants"] = url.Variants
+	}
+	if len(url.Tags) > 0 {
+		resp["tags"] = url.Tags
+	}
+	if url.Title != "" {
+		resp["title"] = url.Title
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func DeleteURL(c *context.Context) {
+	ref := linkRef(c)
+
+	if err := service.NewURLService().DeleteURL(c, ref); err != nil {
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func ListClickEvents(c *context.Context) {
+	ref := linkRef(c)
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	from, err := parseTimeQuery(c, "from")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from, expected RFC3339 timestamp"})
+		return
+	}
+	to, err := parseTimeQuery(c, "to")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to, expected RFC3339 timestamp"})
+		return
+	}
+
+	events, err := service.NewURLService().ListClickEvents(c, ref, from, to, limit)
+	if err != nil {
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": events})
+}
+
+func GetQRCode(c *context.Context) {
+	ref := linkRef(c)
+
+	query := &dtos.QRQuery{
+		Format:     c.Query("format"),
+		Level:      c.Query("level"),
+		Foreground: c.Query("fg"),
+		Background: c.Query("bg"),
+	}
+	if size := c.Query("size"); size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil {
+			c.JSON(errorStatus(service.ErrInvalidQRSize), errorBody(service.ErrInvalidQRSize))
+			return
+		}
+		query.Size = n
+	}
+	if margin := c.Query("margin"); margin != "" {
+		n, err := strconv.Atoi(margin)
+		if err != nil {
+			c.JSON(errorStatus(service.ErrInvalidQRMargin), errorBody(service.ErrInvalidQRMargin))
+			return
+		}
+		query.Margin = &n
+	}
+
+	image, contentType, err := service.NewURLService().GetQRCode(c, ref, query)
+	if err != nil {
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, image)
+}
+
+// clickInfo collects the request metadata recorded with a redirect. The
+// country comes from the proxy header when one is configured and set, and
+// from the GeoIP database otherwise. The sticky variant comes from the
+// visitor's cookie.
+func clickInfo(c *context.Context) *dtos.ClickInfo {
+	click := &dtos.ClickInfo{
+		Referrer:       c.Request.Referer(),
+		UserAgent:      c.Request.UserAgent(),
+		ClientIP:       c.ClientIP(),
+		AcceptLanguage: c.GetHeader("Accept-Language"),
+	}
+	if header := config.AppConfig.CountryHeader; header != "" {
+		click.Country = c.GetHeader(header)
+	}
+	if click.Country == "" {
+		click.Country = service.LookupCountry(click.ClientIP)
+	}
+	if variant, err := c.Cookie(variantCookie); err == nil {
+		click.Variant = variant
+	}
+	return click
+}
+
+// setVariantCookie pins the visitor to the A/B variant they were served.
+// The cookie is scoped to the link's path, so each link keeps its own.
+func setVariantCookie(c *context.Context, click *dtos.ClickInfo) {
+	if click.Variant == "" {
+		return
+	}
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(variantCookie, click.Variant, int(config.AppConfig.VariantCookieTTL.Seconds()),
+		c.Request.URL.Path, "", c.Request.TLS != nil, true)
+}
+
+// setRedirectCacheControl lets browsers and proxies keep permanent redirects
+// for PermanentRedirectMaxAge, or until the link expires if sooner. Every
+// other redirect is sent with no-store so each visit reaches the server and
+// is counted; so are permanent links with targeting rules or variants, whose
+// destination differs per visitor.
+func setRedirectCacheControl(c *context.Context, url *models.URL) {
+	maxAge := config.AppConfig.PermanentRedirectMaxAge
+	if url.ExpiresAt != nil {
+		maxAge = min(maxAge, time.Until(*url.ExpiresAt))
+	}
+
+	if !url.IsPermanent() || len(url.Targeting) > 0 || len(url.Variants) > 0 || maxAge < time.Second {
+		c.Header("Cache-Control", "no-store")
+		return
+	}
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(int(maxAge.Seconds())))
+}
+
+// parseDateQuery reads an RFC 3339 time or a YYYY-MM-DD date. With endOfDay
+// a bare date means the end of that day, so date ranges include their last
+// day.
+func parseDateQuery(raw string, endOfDay bool) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// linkRef reads the link a management route refers to. Links on a branded
+// domain are addressed with ?domain=<hostname>.
+func linkRef(c *context.Context) dtos.LinkRef {
+	return dtos.LinkRef{
+		Domain:    c.Query("domain"),
+		ShortCode: c.Param("code"),
+	}
+}
+
+// parseTimeQuery reads an optional RFC3339 query parameter.
+func parseTimeQuery(c *context.Context, key string) (time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// errorBody renders an error, adding the structured reason code when a
+// destination was rejected by screening.
+func errorBody(err error) gin.H {
+	// unexpected errors, such as database failures, are not shown to clients
+	if errorStatus(err) == http.StatusInternalServerError {
+		return gin.H{"error": "internal server error"}
+	}
+	body := gin.H{"error": err.Error()}
+
+	var screening *service.ScreeningError
+	if errors.As(err, &screening) {
+		body["reason"] = screening.Reason
+	}
+	return body
+}
+
+// errorStatus maps service errors to HTTP status codes.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrShortCodeNotFound),
+		errors.Is(err, service.ErrAPIKeyNotFound),
+		errors.Is(err, service.ErrWorkspaceNotFound),
+		errors.Is(err, service.ErrMemberNotFound),
+		errors.Is(err, service.ErrDomainNotFound),
+		errors.Is(err, service.ErrImportJobNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrUnauthorized),
+		errors.Is(err, service.ErrInvalidAPIKey),
+		errors.Is(err, service.ErrPasswordRequired),
+		errors.Is(err, service.ErrWrongPassword):
+		return http.StatusUnauthorized
+	case errors.Is(err, service.ErrForbidden),
+		errors.Is(err, service.ErrDomainNotAllowed),
+		errors.Is(err, service.ErrAdminOnly),
+		errors.Is(err, service.ErrLinkModerated):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrInvalidURL),
+		errors.Is(err, service.ErrEmptyURL),
+		errors.Is(err, service.ErrInvalidTimeRange),
+		errors.Is(err, service.ErrInvalidInterval),
+		errors.Is(err, service.ErrTooManyBuckets),
+		errors.Is(err, service.ErrInvalidRole),
+		errors.Is(err, service.ErrInvalidWorkspace),
+		errors.Is(err, service.ErrInvalidExpiry),
+		errors.Is(err, service.ErrInvalidMaxClicks),
+		errors.Is(err, service.ErrInvalidDomain),
+		errors.Is(err, service.ErrInvalidQRFormat),
+		errors.Is(err, service.ErrInvalidQRSize),
+		errors.Is(err, service.ErrInvalidQRMargin),
+		errors.Is(err, service.ErrInvalidQRLevel),
+		errors.Is(err, service.ErrInvalidQRColor),
+		errors.Is(err, service.ErrUnsafeURL),
+		errors.Is(err, service.ErrInvalidReason),
+		errors.Is(err, service.ErrInvalidStatus),
+		errors.Is(err, service.ErrInvalidPassword),
+		errors.Is(err, service.ErrInvalidTargeting),
+		errors.Is(err, service.ErrTooManyTargetingRules),
+		errors.Is(err, service.ErrInvalidVariants),
+		errors.Is(err, service.ErrInvalidRedirectType),
+		errors.Is(err, service.ErrEmptyBatch),
+		errors.Is(err, service.ErrInvalidTags),
+		errors.Is(err, service.ErrInvalidImportFormat),
+		errors.Is(err, service.ErrInvalidSort),
+		errors.Is(err, service.ErrInvalidCursor),
+		errors.Is(err, service.ErrInvalidTitle),
+		errors.Is(err, service.ErrInvalidSearch),
+		errors.Is(err, service.ErrNothingToUpdate),
+		errors.Is(err, service.ErrInvalidPlan),
+		errors.Is(err, service.ErrInvalidAlias):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrAliasTaken),
+		errors.Is(err, service.ErrMemberExists),
+		errors.Is(err, service.ErrLastAdmin),
+		errors.Is(err, service.ErrDomainTaken),
+		errors.Is(err, service.ErrDomainInUse),
+		errors.Is(err, service.ErrStatusUnchanged):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrLinkGone):
+		return http.StatusGone
+	case errors.Is(err, service.ErrLinkDisabled):
+		return http.StatusUnavailableForLegalReasons
+	case errors.Is(err, service.ErrTooManyAttempts):
+		return http.StatusTooManyRequests
+	case errors.Is(err, service.ErrBatchTooLarge),
+		errors.Is(err, service.ErrImportTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, service.ErrCodeSpaceExhausted):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
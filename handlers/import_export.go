@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/mohan7-code/url-shortener/config"
+	"github.com/mohan7-code/url-shortener/dtos"
+	"github.com/mohan7-code/url-shortener/models"
+	service "github.com/mohan7-code/url-shortener/services"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	"go.uber.org/zap"
+)
+
+var exportColumns = []string{"alias", "short_url", "destination", "tags", "expires_at", "click_count", "status", "created_at"}
+
+// ImportURLs accepts a CSV or NDJSON file, either as the raw body or as the
+// "file" field of a multipart form, and answers 202 with the job creating
+// its links.
+func ImportURLs(c *context.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, config.AppConfig.ImportMaxBytes)
+
+	var src io.Reader = c.Request.Body
+	filename := ""
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, header, err := c.Request.FormFile("file")
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.JSON(errorStatus(service.ErrImportTooLarge), errorBody(service.ErrImportTooLarge))
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing file field"})
+			return
+		}
+		defer file.Close()
+		src = file
+		filename = header.Filename
+	}
+
+	workspaceID, ok := workspaceQuery(c)
+	if !ok {
+		return
+	}
+	var workspace *uuid.UUID
+	if workspaceID != uuid.Nil {
+		workspace = &workspaceID
+	}
+
+	format := importFormat(c.Query("format"), c.ContentType(), filename)
+	job, err := service.NewImportExportService().StartImport(c, src, format, workspace)
+	if err != nil {
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	c.Header("Location", "/v1/urls/import/"+job.ID.String())
+	c.JSON(http.StatusAccepted, job)
+}
+
+func GetImportJob(c *context.Context) {
+	job, err := service.NewImportExportService().GetImportJob(c, c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// GetImportErrors downloads the CSV of rows a finished import could not
+// create.
+func GetImportErrors(c *context.Context) {
+	job, err := service.NewImportExportService().GetImportJob(c, c.Param("id"))
+	if err != nil {
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+	if job.FinishedAt == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "import job has not finished yet"})
+		return
+	}
+
+	report := job.ErrorReport
+	if report == "" {
+		report = "line,destination,error\n"
+	}
+	c.Header("Content-Disposition", `attachment; filename="import-`+job.ID.String()+`-errors.csv"`)
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", []byte(report))
+}
+
+// ExportURLs streams the caller's links, or a workspace's with
+// ?workspace_id=, as CSV (the default) or NDJSON with ?format=ndjson.
+func ExportURLs(c *context.Context) {
+	format := strings.ToLower(c.DefaultQuery("format", models.FormatCSV))
+	if format != models.FormatCSV && format != models.FormatNDJSON {
+		c.JSON(errorStatus(service.ErrInvalidImportFormat), errorBody(service.ErrInvalidImportFormat))
+		return
+	}
+
+	workspaceID, ok := workspaceQuery(c)
+	if !ok {
+		return
+	}
+
+	csvWriter := csv.NewWriter(c.Writer)
+	jsonEncoder := json.NewEncoder(c.Writer)
+
+	// headers are only sent with the first link, so errors raised before
+	// it can still be answered with a JSON error
+	started := false
+	start := func() error {
+		started = true
+		c.Header("Cache-Control", "no-store")
+		if format == models.FormatNDJSON {
+			c.Header("Content-Type", "application/x-ndjson")
+			c.Header("Content-Disposition", `attachment; filename="links.ndjson"`)
+			c.Status(http.StatusOK)
+			return nil
+		}
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="links.csv"`)
+		c.Status(http.StatusOK)
+		return csvWriter.Write(exportColumns)
+	}
+
+	err := service.NewImportExportService().ExportURLs(c, workspaceID, func(url *models.URL) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+
+		link := exportedLink(url)
+		if format == models.FormatNDJSON {
+			return jsonEncoder.Encode(link)
+		}
+
+		expiresAt := ""
+		if link.ExpiresAt != nil {
+			expiresAt = link.ExpiresAt.UTC().Format(time.RFC3339)
+		}
+		return csvWriter.Write([]string{
+			link.Alias,
+			link.ShortURL,
+			link.Destination,
+			strings.Join(link.Tags, ";"),
+			expiresAt,
+			strconv.FormatInt(link.ClickCount, 10),
+			link.Status,
+			link.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	})
+	if err != nil {
+		if !started {
+			c.JSON(errorStatus(err), errorBody(err))
+			return
+		}
+		// the response is already under way, so the client sees a cut-off file
+		c.Log.Warn("link export interrupted", zap.Error(err))
+		return
+	}
+
+	if !started {
+		if err := start(); err != nil {
+			return
+		}
+	}
+	csvWriter.Flush()
+}
+
+func exportedLink(url *models.URL) dtos.ExportedLink {
+	tags := []string(url.Tags)
+	if tags == nil {
+		tags = []string{}
+	}
+	return dtos.ExportedLink{
+		Alias:       url.ShortCode,
+		ShortURL:    service.ShortURL(url),
+		Destination: url.OriginalURL,
+		Tags:        tags,
+		ExpiresAt:   url.ExpiresAt,
+		ClickCount:  url.ClickCount,
+		Status:      url.Status,
+		CreatedAt:   url.CreatedAt,
+	}
+}
+
+// importFormat picks the import format from ?format=, then the content type,
+// then the uploaded file's extension, falling back to CSV.
+func importFormat(query, contentType, filename string) string {
+	if query != "" {
+		return strings.ToLower(query)
+	}
+
+	filename = strings.ToLower(filename)
+	switch {
+	case contentType == "application/x-ndjson", contentType == "application/ndjson",
+		strings.HasSuffix(filename, ".ndjson"), strings.HasSuffix(filename, ".jsonl"):
+		return models.FormatNDJSON
+	}
+	return models.FormatCSV
+}
+
+// workspaceQuery reads the optional ?workspace_id= filter, answering 400 and
+// returning false when it is not a valid ID.
+func workspaceQuery(c *context.Context) (uuid.UUID, bool) {
+	raw := c.Query("workspace_id")
+	if raw == "" {
+		return uuid.Nil, true
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid workspace_id"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
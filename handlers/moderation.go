@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mohan7-code/url-shortener/dtos"
+	"github.com/mohan7-code/url-shortener/models"
+	service "github.com/mohan7-code/url-shortener/services"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	"go.uber.org/zap"
+)
+
+// interstitial is shown instead of a redirect while a link is under review.
+var interstitial = template.Must(template.New("interstitial").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Warning: this link has been reported</title>
+</head>
+<body style="font-family: sans-serif; max-width: 40em; margin: 4em auto; padding: 0 1em;">
+<h1>This link has been reported</h1>
+<p>The link you followed has been reported as potentially harmful and is being reviewed.
+It leads to:</p>
+<p><code>{{.}}</code></p>
+<p>Only continue if you trust this destination.</p>
+<p><a href="{{.}}" rel="noopener noreferrer nofollow">Continue anyway</a></p>
+</body>
+</html>
+`))
+
+func renderInterstitial(c *context.Context, url *models.URL) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+	if err := interstitial.Execute(c.Writer, url.OriginalURL); err != nil {
+		c.Log.Error("failed to render interstitial", zap.Error(err))
+	}
+}
+
+func ReportLink(c *context.Context) {
+	ref := linkRef(c)
+
+	var req dtos.ReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	if err := service.NewModerationService().ReportLink(c, ref, &req); err != nil {
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "report received"})
+}
+
+func ListReports(c *context.Context) {
+	page, _ := strconv.Atoi(c.Query("page"))
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	query := &dtos.ReportQuery{
+		Resolved: c.Query("state") == "resolved",
+		Page:     page,
+		Limit:    limit,
+	}
+
+	resp, err := service.NewModerationService().ListReports(c, query)
+	if err != nil {
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func ModerateURL(c *context.Context) {
+	ref := linkRef(c)
+
+	var req dtos.ModerationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	url, err := service.NewModerationService().SetLinkStatus(c, ref, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"short_url":    service.ShortURL(url),
+		"original_url": url.OriginalURL,
+		"status":       url.Status,
+	})
+}
+
+func ListAuditTrail(c *context.Context) {
+	ref := linkRef(c)
+
+	actions, err := service.NewModerationService().ListAuditTrail(c, ref)
+	if err != nil {
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": actions})
+}
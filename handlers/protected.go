@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"errors"
+	"html/template"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/mohan7-code/url-shortener/models"
+	service "github.com/mohan7-code/url-shortener/services"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	"go.uber.org/zap"
+)
+
+// passwordForm asks for a protected link's password and posts it back to the
+// link's own URL.
+var passwordForm = template.Must(template.New("password").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Password required</title>
+</head>
+<body style="font-family: sans-serif; max-width: 24em; margin: 4em auto; padding: 0 1em;">
+<h1>Password required</h1>
+<p>This link is password protected.</p>
+{{if .}}<p style="color: #b00020;">{{.}}</p>{{end}}
+<form method="post">
+<input type="password" name="password" autocomplete="off" required autofocus>
+<button type="submit">Continue</button>
+</form>
+</body>
+</html>
+`))
+
+func renderPasswordForm(c *context.Context, status int, message string) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Header("Cache-Control", "no-store")
+	c.Status(status)
+	if err := passwordForm.Execute(c.Writer, message); err != nil {
+		c.Log.Error("failed to render password form", zap.Error(err))
+	}
+}
+
+// UnlockURL checks the password submitted from the form served by
+// RedirectURL and redirects when it matches.
+func UnlockURL(c *context.Context) {
+	shortCode := c.Param("shortCode")
+
+	click := clickInfo(c)
+	url, err := service.NewURLService().UnlockURL(c, c.Request.Host, shortCode, c.PostForm("password"), click)
+	if err != nil {
+		var lockout *service.LockoutError
+		switch {
+		case errors.Is(err, service.ErrWrongPassword):
+			renderPasswordForm(c, http.StatusUnauthorized, err.Error())
+		case errors.As(err, &lockout):
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(lockout.RetryAfter.Seconds()))))
+			renderPasswordForm(c, http.StatusTooManyRequests, err.Error())
+		default:
+			c.JSON(errorStatus(err), errorBody(err))
+		}
+		return
+	}
+
+	setVariantCookie(c, click)
+
+	if url.Status == models.StatusUnderReview {
+		renderInterstitial(c, url)
+		return
+	}
+
+	// always 303 whatever the link's redirect type: a 307 or 308 would
+	// replay the password form to the destination
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusSeeOther, url.OriginalURL)
+}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/mohan7-code/url-shortener/dtos"
+	service "github.com/mohan7-code/url-shortener/services"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+)
+
+func CreateWorkspace(c *context.Context) {
+	var req dtos.WorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	workspace, err := service.NewWorkspaceService().CreateWorkspace(c, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, workspace)
+}
+
+func ListWorkspaces(c *context.Context) {
+	workspaces, err := service.NewWorkspaceService().ListWorkspaces(c)
+	if err != nil {
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": workspaces})
+}
+
+func ListWorkspaceMembers(c *context.Context) {
+	workspaceID, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	members, err := service.NewWorkspaceService().ListMembers(c, workspaceID)
+	if err != nil {
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": members})
+}
+
+func AddWorkspaceMember(c *context.Context) {
+	workspaceID, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dtos.WorkspaceMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	member, err := service.NewWorkspaceService().AddMember(c, workspaceID, &req)
+	if err != nil {
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, member)
+}
+
+func UpdateWorkspaceMember(c *context.Context) {
+	workspaceID, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+	memberID, ok := uuidParam(c, "memberId")
+	if !ok {
+		return
+	}
+
+	var req dtos.WorkspaceMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	member, err := service.NewWorkspaceService().UpdateMemberRole(c, workspaceID, memberID, req.Role)
+	if err != nil {
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+func RemoveWorkspaceMember(c *context.Context) {
+	workspaceID, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+	memberID, ok := uuidParam(c, "memberId")
+	if !ok {
+		return
+	}
+
+	if err := service.NewWorkspaceService().RemoveMember(c, workspaceID, memberID); err != nil {
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+func CreateWorkspaceDomain(c *context.Context) {
+	workspaceID, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dtos.DomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	domain, err := service.NewDomainService().RegisterDomain(c, workspaceID, req.Hostname)
+	if err != nil {
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, domain)
+}
+
+func ListWorkspaceDomains(c *context.Context) {
+	workspaceID, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+
+	domains, err := service.NewDomainService().ListDomains(c, workspaceID)
+	if err != nil {
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": domains})
+}
+
+func DeleteWorkspaceDomain(c *context.Context) {
+	workspaceID, ok := uuidParam(c, "id")
+	if !ok {
+		return
+	}
+	domainID, ok := uuidParam(c, "domainId")
+	if !ok {
+		return
+	}
+
+	if err := service.NewDomainService().RemoveDomain(c, workspaceID, domainID); err != nil {
+		c.JSON(errorStatus(err), errorBody(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// uuidParam parses a UUID path parameter, answering 400 when it is malformed.
+func uuidParam(c *context.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -11,8 +11,11 @@ import (
 
 	"github.com/mohan7-code/url-shortener/config"
 	"github.com/mohan7-code/url-shortener/database"
+	"github.com/mohan7-code/url-shortener/middleware"
 	"github.com/mohan7-code/url-shortener/routes"
+	service "github.com/mohan7-code/url-shortener/services"
 	"github.com/mohan7-code/url-shortener/utils/cache"
+	appctx "github.com/mohan7-code/url-shortener/utils/context"
 )
 
 func main() {
@@ -28,6 +31,27 @@ func main() {
 		MaxDBConn: cnf.MaxDBConn,
 	})
 	cache.SetRedis()
+
+	bgCtx := appctx.NewBackground(middleware.Logger())
+
+	sweeper := service.NewExpirySweeper(cnf.ExpirySweepInterval, cnf.ExpiredRetention)
+	sweeper.Start(bgCtx)
+
+	clicks := service.NewClickBuffer(cnf.ClickBufferSize, cnf.ClickFlushBatch, cnf.ClickFlushInterval)
+	clicks.Start(bgCtx)
+
+	var blocklist *service.Blocklist
+	if cnf.BlocklistPath != "" {
+		blocklist = service.NewBlocklist(cnf.BlocklistPath, cnf.BlocklistReloadInterval)
+		blocklist.Start(bgCtx)
+	}
+
+	var geo *service.GeoIP
+	if cnf.GeoIPPath != "" {
+		geo = service.NewGeoIP(cnf.GeoIPPath)
+		geo.Start(bgCtx)
+	}
+
 	r := routes.GetRouter()
 
 	server := &http.Server{
@@ -52,7 +76,20 @@ func main() {
 
 	// Graceful shutdown
 	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Shutdown failed: %v", err)
+		log.Printf("Shutdown failed: %v", err)
+	}
+
+	// no more uploads can arrive, let running imports record their progress
+	service.StopImports()
+
+	// no more redirects can arrive, write out the clicks still in memory
+	clicks.Stop()
+	sweeper.Stop()
+	if blocklist != nil {
+		blocklist.Stop()
+	}
+	if geo != nil {
+		geo.Stop()
 	}
 
 	sqlDB, _ := database.DB.DB()
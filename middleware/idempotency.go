@@ -0,0 +1,200 @@
+package middleware
+
+import (
+	"bytes"
+	stdctx "context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mohan7-code/url-shortener/config"
+	"github.com/mohan7-code/url-shortener/utils/cache"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	idempotencyHeader = "Idempotency-Key"
+	maxIdempotencyKey = 255
+
+	// idempotencyLockTTL bounds how long a request that never finishes, for
+	// example because the process died, keeps its key locked. Requests still
+	// running refresh it every idempotencyLockRefresh.
+	idempotencyLockTTL     = time.Minute
+	idempotencyLockRefresh = idempotencyLockTTL / 3
+)
+
+// idempotentResponse is the value stored under an idempotency key. Status is
+// zero while the first request is still being handled.
+type idempotentResponse struct {
+	Fingerprint string `json:"fingerprint"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// bodyRecorder copies everything the handler writes so it can be stored.
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotent replays the stored response when a request carries an
+// Idempotency-Key the caller already used on this route within
+// IdempotencyTTL. Such requests are answered with 413 when their body is
+// larger than IdempotencyMaxBody. Reusing a key with a different body is answered with 422,
+// and a retry that arrives while the first request is still running with
+// 409. Responses with a 5xx status are not stored, so those requests can be
+// retried with the same key. It is meant to be wrapped by RequireAuth; if
+// Redis is unavailable requests are handled without replay protection.
+func Idempotent(next func(*context.Context)) func(*context.Context) {
+	return func(c *context.Context) {
+		key := c.GetHeader(idempotencyHeader)
+		if key == "" {
+			next(c)
+			return
+		}
+		if len(key) > maxIdempotencyKey {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be at most 255 characters"})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, config.AppConfig.IdempotencyMaxBody))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body is too large"})
+			} else {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+			}
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		sum := sha256.Sum256(body)
+		fingerprint := hex.EncodeToString(sum[:])
+		sum = sha256.Sum256([]byte(key))
+		redisKey := "idempotency:" + c.OwnerID.String() + ":" + c.FullPath() + ":" + hex.EncodeToString(sum[:])
+
+		rdb := cache.New().Client
+		pending, _ := json.Marshal(&idempotentResponse{Fingerprint: fingerprint})
+
+		acquired, err := rdb.SetNX(c, redisKey, pending, idempotencyLockTTL).Result()
+		if err != nil {
+			c.Log.Warn("idempotency store unavailable", zap.Error(err))
+			next(c)
+			return
+		}
+		if !acquired {
+			replayResponse(c, rdb, redisKey, fingerprint)
+			return
+		}
+
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		stop := keepLocked(c, rdb, redisKey)
+		next(c)
+		stop()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			if err := rdb.Del(c, redisKey).Err(); err != nil {
+				c.Log.Warn("failed to release idempotency key", zap.Error(err))
+			}
+			return
+		}
+
+		stored, _ := json.Marshal(&idempotentResponse{
+			Fingerprint: fingerprint,
+			Status:      status,
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		})
+		if err := rdb.Set(c, redisKey, stored, config.AppConfig.IdempotencyTTL).Err(); err != nil {
+			c.Log.Warn("failed to store idempotent response", zap.Error(err))
+		}
+	}
+}
+
+// keepLocked extends the pending lock until the returned func is called, so
+// a slow request, such as a large batch, keeps its key while it runs. The
+// func waits for the refresher to exit, so no refresh can land after it.
+func keepLocked(c *context.Context, rdb *redis.Client, redisKey string) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	reqCtx := stdctx.WithoutCancel(c.Request.Context())
+
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(idempotencyLockRefresh)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := rdb.Expire(reqCtx, redisKey, idempotencyLockTTL).Err(); err != nil {
+					c.Log.Warn("failed to refresh idempotency lock", zap.Error(err))
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// replayResponse answers a request whose key is already taken.
+func replayResponse(c *context.Context, rdb *redis.Client, redisKey, fingerprint string) {
+	defer c.Abort()
+
+	raw, err := rdb.Get(c, redisKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// the first request failed and released the key between our calls
+		c.JSON(http.StatusConflict, gin.H{"error": "a request with this Idempotency-Key is in progress, retry shortly"})
+		return
+	}
+	var stored idempotentResponse
+	if err == nil {
+		err = json.Unmarshal(raw, &stored)
+	}
+	if err != nil {
+		c.Log.Warn("failed to read idempotent response", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check Idempotency-Key"})
+		return
+	}
+
+	if stored.Fingerprint != fingerprint {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key was already used with a different request body"})
+		return
+	}
+	if stored.Status == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "a request with this Idempotency-Key is in progress, retry shortly"})
+		return
+	}
+
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(stored.Status, stored.ContentType, stored.Body)
+}
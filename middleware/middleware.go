@@ -1,22 +1,25 @@
 package middleware
 
 import (
+	"errors"
+	"math"
 	"net/http"
-	"sync"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/mohan7-code/url-shortener/config"
 	"github.com/mohan7-code/url-shortener/database"
+	service "github.com/mohan7-code/url-shortener/services"
+	"github.com/mohan7-code/url-shortener/utils/cache"
 	context "github.com/mohan7-code/url-shortener/utils/context"
+	"github.com/mohan7-code/url-shortener/utils/ratelimit"
 	"go.uber.org/zap"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/time/rate"
 )
 
-var (
-	logger   *zap.Logger
-	mu       sync.Mutex
-	limiters = make(map[string]*rate.Limiter)
-)
+var logger *zap.Logger
 
 func init() {
 	var err error
@@ -29,31 +32,130 @@ func init() {
 
 }
 
+// Logger returns the shared application logger.
+func Logger() *zap.Logger {
+	return logger
+}
+
+// MiddleWare wraps a handler with the default API rate limit.
 func MiddleWare(next func(*context.Context)) gin.HandlerFunc {
+	return RateLimited(config.RouteAPI, next)
+}
+
+// RateLimited wraps a handler with the rate limit of the given route class.
+// Authenticated requests are limited per API key, scaled by the key's plan,
+// and anonymous ones per client IP.
+func RateLimited(route string, next func(*context.Context)) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ip := c.ClientIP()
-
-		//simple Rate limiting using Token Bucket method
-		mu.Lock()
-		limiter, exists := limiters[ip]
-		if !exists {
-			limiter = rate.NewLimiter(1, 5)
-			limiters[ip] = limiter
+		appCtx := &context.Context{
+			DB:      database.New(),
+			Log:     logger,
+			Context: c,
 		}
-		mu.Unlock()
 
-		if !limiter.Allow() {
-			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too Many Requests, Try after sometime"})
+		subject := "ip:" + c.ClientIP()
+
+		if rawKey := apiKeyFromRequest(c); rawKey != "" {
+			key, err := service.NewAPIKeyService().Authenticate(appCtx, rawKey)
+			if err != nil {
+				// failed attempts still count against the IP so keys cannot be guessed freely
+				if !allow(appCtx, route, subject, "") {
+					return
+				}
+				if errors.Is(err, service.ErrInvalidAPIKey) {
+					c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				} else {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify api key"})
+				}
+				c.Abort()
+				return
+			}
+
+			appCtx.OwnerID = key.OwnerID
+			appCtx.APIKeyID = key.ID
+			appCtx.Plan = key.Plan
+			appCtx.IsAdmin = key.IsAdmin
+			subject = "key:" + key.ID.String()
+		}
+
+		if !allow(appCtx, route, subject, appCtx.Plan) {
+			return
+		}
+
+		next(appCtx)
+	}
+}
+
+// allow counts the request against the subject's quota for the route and
+// sets the X-RateLimit-* headers. It answers 429 and returns false when the
+// quota is used up. If Redis is unavailable the request is let through.
+func allow(c *context.Context, route, subject, plan string) bool {
+	limit, ok := config.AppConfig.RateLimits[route]
+	if !ok {
+		limit = config.AppConfig.RateLimits[config.RouteAPI]
+	}
+	if multiplier, ok := config.AppConfig.RateLimitPlans[plan]; ok {
+		limit.Requests = max(1, int(float64(limit.Requests)*multiplier))
+	}
+
+	key := "ratelimit:" + route + ":" + subject
+	result, err := ratelimit.Allow(c, cache.New().Client, key, limit.Requests, limit.Period)
+	if err != nil {
+		c.Log.Warn("rate limiter unavailable", zap.String("key", key), zap.Error(err))
+		return true
+	}
+
+	c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+	if !result.Allowed {
+		c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too Many Requests, Try after sometime"})
+		c.Abort()
+		return false
+	}
+	return true
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// RequireAuth rejects requests that did not present a valid API key.
+func RequireAuth(next func(*context.Context)) func(*context.Context) {
+	return func(c *context.Context) {
+		if !c.IsAuthenticated() {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": service.ErrUnauthorized.Error()})
 			c.Abort()
 			return
 		}
 
-		appCtx := &context.Context{
-			DB:      database.New(),
-			Log:     logger,
-			Context: c,
+		next(c)
+	}
+}
+
+// RequireAdmin rejects requests whose API key is not an admin key. It is
+// meant to be wrapped by RequireAuth.
+func RequireAdmin(next func(*context.Context)) func(*context.Context) {
+	return func(c *context.Context) {
+		if !c.IsAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": service.ErrAdminOnly.Error()})
+			c.Abort()
+			return
 		}
 
-		next(appCtx)
+		next(c)
+	}
+}
+
+// apiKeyFromRequest reads the key from "Authorization: Bearer <key>" or the
+// X-API-Key header.
+func apiKeyFromRequest(c *gin.Context) string {
+	if auth := c.GetHeader("Authorization"); auth != "" {
+		if token, ok := strings.CutPrefix(auth, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
 	}
+	return strings.TrimSpace(c.GetHeader("X-API-Key"))
 }
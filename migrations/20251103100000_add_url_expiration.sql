@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE url_shortner
+    ADD COLUMN expires_at TIMESTAMPTZ,
+    ADD COLUMN max_clicks BIGINT;
+
+CREATE INDEX idx_url_shortner_expires_at ON url_shortner(expires_at) WHERE expires_at IS NOT NULL;
+
+CREATE TABLE url_shortner_archive (
+    id UUID PRIMARY KEY,
+    short_code VARCHAR(10) NOT NULL,
+    original_url TEXT NOT NULL,
+    data JSONB NOT NULL,
+    archived_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_url_shortner_archive_short_code ON url_shortner_archive(short_code);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS url_shortner_archive;
+ALTER TABLE url_shortner
+    DROP COLUMN IF EXISTS expires_at,
+    DROP COLUMN IF EXISTS max_clicks;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE click_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    url_id UUID NOT NULL REFERENCES url_shortner(id) ON DELETE CASCADE,
+    clicked_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    referrer TEXT,
+    user_agent TEXT,
+    ip_hash VARCHAR(64),
+    accept_language TEXT
+);
+
+CREATE INDEX idx_click_events_url_id_clicked_at ON click_events(url_id, clicked_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS click_events;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE click_events
+    ADD COLUMN referrer_host TEXT,
+    ADD COLUMN browser VARCHAR(50),
+    ADD COLUMN os VARCHAR(50),
+    ADD COLUMN device VARCHAR(20),
+    ADD COLUMN country VARCHAR(2);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE click_events
+    DROP COLUMN IF EXISTS referrer_host,
+    DROP COLUMN IF EXISTS browser,
+    DROP COLUMN IF EXISTS os,
+    DROP COLUMN IF EXISTS device,
+    DROP COLUMN IF EXISTS country;
+-- +goose StatementEnd
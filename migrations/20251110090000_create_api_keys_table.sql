@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_id UUID NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    key_prefix VARCHAR(12) NOT NULL,
+    key_hash VARCHAR(64) UNIQUE NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_api_keys_owner_id ON api_keys(owner_id);
+
+ALTER TABLE url_shortner ADD COLUMN owner_id UUID;
+
+CREATE INDEX idx_url_shortner_owner_id_created_at ON url_shortner(owner_id, created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE url_shortner DROP COLUMN IF EXISTS owner_id;
+DROP TABLE IF EXISTS api_keys;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE workspaces (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE workspace_members (
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    member_id UUID NOT NULL,
+    role VARCHAR(10) NOT NULL CHECK (role IN ('admin', 'editor', 'viewer')),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (workspace_id, member_id)
+);
+
+CREATE INDEX idx_workspace_members_member_id ON workspace_members(member_id);
+
+ALTER TABLE url_shortner ADD COLUMN workspace_id UUID REFERENCES workspaces(id);
+
+CREATE INDEX idx_url_shortner_workspace_id_created_at ON url_shortner(workspace_id, created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE url_shortner DROP COLUMN IF EXISTS workspace_id;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE domains (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    hostname VARCHAR(253) UNIQUE NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_domains_workspace_id ON domains(workspace_id);
+
+-- links on the default domain keep a NULL domain
+ALTER TABLE url_shortner ADD COLUMN domain VARCHAR(253) REFERENCES domains(hostname);
+
+ALTER TABLE url_shortner DROP CONSTRAINT IF EXISTS url_shortner_short_code_key;
+DROP INDEX IF EXISTS idx_short_code;
+
+CREATE UNIQUE INDEX idx_url_shortner_domain_short_code ON url_shortner(COALESCE(domain, ''), short_code);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_url_shortner_domain_short_code;
+ALTER TABLE url_shortner DROP COLUMN IF EXISTS domain;
+ALTER TABLE url_shortner ADD CONSTRAINT url_shortner_short_code_key UNIQUE (short_code);
+CREATE UNIQUE INDEX idx_short_code ON url_shortner(short_code);
+DROP TABLE IF EXISTS domains;
+-- +goose StatementEnd
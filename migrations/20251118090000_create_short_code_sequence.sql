@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- feeds the sequence and hashids short code strategies
+CREATE SEQUENCE url_short_code_seq AS BIGINT START WITH 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP SEQUENCE IF EXISTS url_short_code_seq;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- a destination may now have any number of codes; reuse is looked up per owner or workspace
+ALTER TABLE url_shortner DROP CONSTRAINT IF EXISTS url_shortner_original_url_key;
+
+-- hash indexes only support equality but have no size limit on long URLs
+CREATE INDEX idx_url_shortner_original_url ON url_shortner USING hash (original_url);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_url_shortner_original_url;
+ALTER TABLE url_shortner ADD CONSTRAINT url_shortner_original_url_key UNIQUE (original_url);
+-- +goose StatementEnd
@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE url_shortner ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'active'
+    CHECK (status IN ('active', 'disabled', 'under_review'));
+
+ALTER TABLE api_keys ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE link_reports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    url_id UUID NOT NULL REFERENCES url_shortner(id) ON DELETE CASCADE,
+    reason VARCHAR(16) NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    reporter_ip_hash VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    resolved_at TIMESTAMPTZ,
+    resolution VARCHAR(16)
+);
+
+CREATE INDEX idx_link_reports_url_id ON link_reports(url_id);
+CREATE INDEX idx_link_reports_open ON link_reports(created_at) WHERE resolved_at IS NULL;
+
+-- no foreign key: the trail must outlive deleted links
+CREATE TABLE moderation_actions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    url_id UUID NOT NULL,
+    short_code VARCHAR(10) NOT NULL,
+    domain VARCHAR(253),
+    actor_id UUID,
+    from_status VARCHAR(16) NOT NULL,
+    to_status VARCHAR(16) NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_moderation_actions_url_id ON moderation_actions(url_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS moderation_actions;
+DROP TABLE IF EXISTS link_reports;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS is_admin;
+ALTER TABLE url_shortner DROP COLUMN IF EXISTS status;
+-- +goose StatementEnd
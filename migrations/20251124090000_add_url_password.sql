@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE url_shortner ADD COLUMN password_hash VARCHAR(60);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE url_shortner DROP COLUMN IF EXISTS password_hash;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE url_shortner ADD COLUMN targeting JSONB;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE url_shortner DROP COLUMN IF EXISTS targeting;
+-- +goose StatementEnd
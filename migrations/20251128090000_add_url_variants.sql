@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE url_shortner ADD COLUMN variants JSONB;
+
+ALTER TABLE click_events ADD COLUMN variant VARCHAR(32);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE click_events DROP COLUMN IF EXISTS variant;
+
+ALTER TABLE url_shortner DROP COLUMN IF EXISTS variants;
+-- +goose StatementEnd
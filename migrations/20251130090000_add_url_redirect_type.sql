@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE url_shortner ADD COLUMN redirect_type SMALLINT NOT NULL DEFAULT 302
+    CHECK (redirect_type IN (301, 302, 307, 308));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE url_shortner DROP COLUMN IF EXISTS redirect_type;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE url_shortner ADD COLUMN tags JSONB;
+
+CREATE INDEX idx_url_shortner_tags ON url_shortner USING GIN (tags);
+
+CREATE TABLE import_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_id UUID NOT NULL,
+    workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE,
+    format VARCHAR(8) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+    total_rows INT NOT NULL DEFAULT 0,
+    succeeded_rows INT NOT NULL DEFAULT 0,
+    failed_rows INT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    error_report TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_import_jobs_owner_id ON import_jobs(owner_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS import_jobs;
+DROP INDEX IF EXISTS idx_url_shortner_tags;
+ALTER TABLE url_shortner DROP COLUMN IF EXISTS tags;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- keyset pagination compares (column, id) tuples, which never match NULLs
+UPDATE url_shortner SET click_count = 0 WHERE click_count IS NULL;
+UPDATE url_shortner SET created_at = NOW() WHERE created_at IS NULL;
+UPDATE url_shortner SET last_accessed_at = created_at WHERE last_accessed_at IS NULL;
+
+ALTER TABLE url_shortner
+    ALTER COLUMN click_count SET NOT NULL,
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN last_accessed_at SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_url_shortner_owner_id_created_at;
+DROP INDEX IF EXISTS idx_url_shortner_workspace_id_created_at;
+
+CREATE INDEX idx_url_shortner_owner_id_created_at ON url_shortner(owner_id, created_at, id);
+CREATE INDEX idx_url_shortner_workspace_id_created_at ON url_shortner(workspace_id, created_at, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_url_shortner_workspace_id_created_at;
+DROP INDEX IF EXISTS idx_url_shortner_owner_id_created_at;
+
+CREATE INDEX idx_url_shortner_owner_id_created_at ON url_shortner(owner_id, created_at);
+CREATE INDEX idx_url_shortner_workspace_id_created_at ON url_shortner(workspace_id, created_at);
+
+ALTER TABLE url_shortner
+    ALTER COLUMN click_count DROP NOT NULL,
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN last_accessed_at DROP NOT NULL;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE url_shortner ADD COLUMN title TEXT NOT NULL DEFAULT '';
+
+-- destinations are split on punctuation so "example" matches
+-- https://www.example.com/pricing
+ALTER TABLE url_shortner ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', short_code), 'A') ||
+    setweight(to_tsvector('simple', title), 'A') ||
+    setweight(jsonb_to_tsvector('simple', COALESCE(tags, '[]'::jsonb), '["string"]'), 'B') ||
+    setweight(to_tsvector('simple', regexp_replace(original_url, '[^[:alnum:]]+', ' ', 'g')), 'C')
+) STORED;
+
+CREATE INDEX idx_url_shortner_search_vector ON url_shortner USING GIN (search_vector);
+
+-- trigram indexes serve partial words such as "pric" and typo tolerant ranking
+CREATE INDEX idx_url_shortner_short_code_trgm ON url_shortner USING GIN (short_code gin_trgm_ops);
+CREATE INDEX idx_url_shortner_original_url_trgm ON url_shortner USING GIN (original_url gin_trgm_ops);
+CREATE INDEX idx_url_shortner_title_trgm ON url_shortner USING GIN (title gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_url_shortner_title_trgm;
+DROP INDEX IF EXISTS idx_url_shortner_original_url_trgm;
+DROP INDEX IF EXISTS idx_url_shortner_short_code_trgm;
+DROP INDEX IF EXISTS idx_url_shortner_search_vector;
+ALTER TABLE url_shortner DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE url_shortner DROP COLUMN IF EXISTS title;
+-- +goose StatementEnd
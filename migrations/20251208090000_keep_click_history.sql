@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- no foreign key: clicks of archived links are kept and still match
+-- url_shortner_archive.id; deleting a link removes its clicks explicitly
+ALTER TABLE click_events DROP CONSTRAINT IF EXISTS click_events_url_id_fkey;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM click_events WHERE url_id NOT IN (SELECT id FROM url_shortner);
+ALTER TABLE click_events ADD CONSTRAINT click_events_url_id_fkey
+    FOREIGN KEY (url_id) REFERENCES url_shortner(id) ON DELETE CASCADE;
+-- +goose StatementEnd
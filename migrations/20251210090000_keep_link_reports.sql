@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- no foreign key: reports are part of the moderation trail and must outlive
+-- archived and deleted links
+ALTER TABLE link_reports DROP CONSTRAINT IF EXISTS link_reports_url_id_fkey;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM link_reports WHERE url_id NOT IN (SELECT id FROM url_shortner);
+ALTER TABLE link_reports ADD CONSTRAINT link_reports_url_id_fkey
+    FOREIGN KEY (url_id) REFERENCES url_shortner(id) ON DELETE CASCADE;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the audit trail of an archived or deleted link is found by its code
+CREATE INDEX idx_moderation_actions_short_code ON moderation_actions(short_code, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_moderation_actions_short_code;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type APIKey struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	OwnerID   uuid.UUID  `json:"owner_id"`
+	Name      string     `json:"name"`
+	KeyPrefix string     `json:"key_prefix"`
+	KeyHash   string     `json:"-"`
+	Plan      string     `gorm:"default:free" json:"plan"`
+	IsAdmin   bool       `json:"is_admin"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
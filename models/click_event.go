@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ClickEvent struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	URLID          uuid.UUID `json:"url_id"`
+	ClickedAt      time.Time `json:"clicked_at"`
+	Referrer       string    `json:"referrer"`
+	UserAgent      string    `json:"user_agent"`
+	IPHash         string    `json:"ip_hash"`
+	AcceptLanguage string    `json:"accept_language"`
+	ReferrerHost   string    `json:"referrer_host"`
+	Browser        string    `json:"browser"`
+	OS             string    `json:"os"`
+	Device         string    `json:"device"`
+	Country        string    `json:"country"`
+	Variant        string    `json:"variant,omitempty"`
+}
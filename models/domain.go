@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Domain struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	WorkspaceID uuid.UUID `json:"workspace_id"`
+	Hostname    string    `json:"hostname"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
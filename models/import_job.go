@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Statuses of an import job.
+const (
+	ImportPending   = "pending"
+	ImportRunning   = "running"
+	ImportCompleted = "completed"
+	ImportFailed    = "failed"
+)
+
+// Formats accepted by imports and produced by exports.
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// ImportJob tracks a file of links being created in the background. Error is
+// set when the whole file could not be read; rows that failed on their own
+// are listed in ErrorReport, a CSV of row number, destination and error.
+type ImportJob struct {
+	ID            uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	OwnerID       uuid.UUID  `json:"owner_id"`
+	WorkspaceID   *uuid.UUID `json:"workspace_id,omitempty"`
+	Format        string     `json:"format"`
+	Status        string     `gorm:"default:pending" json:"status"`
+	TotalRows     int        `json:"total_rows"`
+	SucceededRows int        `json:"succeeded_rows"`
+	FailedRows    int        `json:"failed_rows"`
+	Error         string     `json:"error,omitempty"`
+	ErrorReport   string     `json:"-"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+}
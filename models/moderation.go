@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ReportPhishing = "phishing"
+	ReportMalware  = "malware"
+	ReportSpam     = "spam"
+	ReportOther    = "other"
+)
+
+// LinkReport is an abuse report filed against a link. Open reports have no
+// ResolvedAt; a moderation action resolves them with the status it set.
+type LinkReport struct {
+	ID             uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	URLID          uuid.UUID  `json:"url_id"`
+	Reason         string     `json:"reason"`
+	Details        string     `json:"details"`
+	ReporterIPHash string     `json:"-"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	Resolution     *string    `json:"resolution,omitempty"`
+}
+
+// ReportWithLink is a report together with the link it is about.
+type ReportWithLink struct {
+	LinkReport
+	ShortCode   string  `json:"short_code"`
+	Domain      *string `json:"domain,omitempty"`
+	OriginalURL string  `json:"original_url"`
+	Status      string  `json:"status"`
+}
+
+// ModerationAction is one entry of the audit trail: a change of a link's
+// status. ActorID is the admin's owner ID, or nil for automatic actions.
+type ModerationAction struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	URLID      uuid.UUID  `json:"url_id"`
+	ShortCode  string     `json:"short_code"`
+	Domain     *string    `json:"domain,omitempty"`
+	ActorID    *uuid.UUID `json:"actor_id,omitempty"`
+	FromStatus string     `json:"from_status"`
+	ToStatus   string     `json:"to_status"`
+	Note       string     `json:"note"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
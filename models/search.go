@@ -0,0 +1,28 @@
+package models
+
+// SearchHit is a link found by a search, with its relevance and the
+// ts_headline of its title. TitleHeadline marks matches with
+// HeadlineStart and HeadlineStop.
+type SearchHit struct {
+	URL
+	Rank          float64          `json:"rank"`
+	TitleHeadline string           `json:"-"`
+	Highlights    SearchHighlights `gorm:"-" json:"highlights"`
+}
+
+// Delimiters ts_headline puts around matches. Titles with control characters
+// are refused, so they cannot occur in a title and matches can be marked up
+// after the text is HTML escaped.
+const (
+	HeadlineStart = "\x02"
+	HeadlineStop  = "\x03"
+)
+
+// SearchHighlights holds the matched fields as HTML escaped text with each
+// match wrapped in <mark>. Fields without a match are left empty.
+type SearchHighlights struct {
+	ShortCode   string   `json:"short_code,omitempty"`
+	OriginalURL string   `json:"original_url,omitempty"`
+	Title       string   `json:"title,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// Tags label a link for filtering and bulk management. They are stored as a
+// JSONB array.
+type Tags []string
+
+func (t Tags) Value() (driver.Value, error) {
+	if len(t) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(t)
+}
+
+func (t *Tags) Scan(value interface{}) error {
+	var raw []byte
+	switch val := value.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case []byte:
+		raw = val
+	case string:
+		raw = []byte(val)
+	default:
+		return errors.New("unsupported type for tags")
+	}
+	return json.Unmarshal(raw, t)
+}
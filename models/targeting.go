@@ -0,0 +1,48 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// TargetingRule sends visitors matching every condition it sets to
+// Destination. Within one condition any listed value matches: OS values are
+// operating system families such as "ios" or "android", Device values are
+// "mobile", "tablet" or "desktop", Language values are language tags such
+// as "de" or "pt-BR" compared with the visitor's preferred language, and
+// Country values are ISO 3166-1 alpha-2 codes such as "US".
+type TargetingRule struct {
+	OS          []string `json:"os,omitempty"`
+	Device      []string `json:"device,omitempty"`
+	Language    []string `json:"language,omitempty"`
+	Country     []string `json:"country,omitempty"`
+	Destination string   `json:"destination"`
+}
+
+// TargetingRules are evaluated in order and the first match wins. Visitors
+// matching no rule go to the link's OriginalURL. It is stored as JSONB.
+type TargetingRules []TargetingRule
+
+func (r TargetingRules) Value() (driver.Value, error) {
+	if len(r) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(r)
+}
+
+func (r *TargetingRules) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
+		*r = nil
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return errors.New("unsupported type for targeting rules")
+	}
+	return json.Unmarshal(raw, r)
+}
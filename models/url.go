@@ -1,16 +1,65 @@
 package models
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Moderation statuses of a link. Only active links redirect normally.
+const (
+	StatusActive      = "active"
+	StatusDisabled    = "disabled"
+	StatusUnderReview = "under_review"
+)
+
 type URL struct {
-	ID             uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
-	ShortCode      string    `json:"short_code"`
-	OriginalURL    string    `json:"original_url"`
-	ClickCount     int64     `json:"click_count"`
-	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
-	LastAccessedAt time.Time `json:"last_accessed_at"`
+	ID             uuid.UUID      `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	ShortCode      string         `json:"short_code"`
+	OriginalURL    string         `json:"original_url"`
+	Title          string         `json:"title,omitempty"`
+	ClickCount     int64          `json:"click_count"`
+	CreatedAt      time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	LastAccessedAt time.Time      `json:"last_accessed_at"`
+	ExpiresAt      *time.Time     `json:"expires_at,omitempty"`
+	MaxClicks      *int64         `json:"max_clicks,omitempty"`
+	OwnerID        *uuid.UUID     `json:"owner_id,omitempty"`
+	WorkspaceID    *uuid.UUID     `json:"workspace_id,omitempty"`
+	Domain         *string        `json:"domain,omitempty"`
+	Status         string         `gorm:"default:active" json:"status"`
+	PasswordHash   *string        `json:"-"`
+	Targeting      TargetingRules `gorm:"type:jsonb" json:"targeting,omitempty"`
+	Variants       Variants       `gorm:"type:jsonb" json:"variants,omitempty"`
+	RedirectType   int            `gorm:"default:302" json:"redirect_type"`
+	Tags           Tags           `gorm:"type:jsonb" json:"tags,omitempty"`
+}
+
+// DomainName returns the link's branded domain, or "" for the default domain.
+func (u *URL) DomainName() string {
+	if u.Domain == nil {
+		return ""
+	}
+	return *u.Domain
+}
+
+// IsProtected reports whether visitors must enter a password to follow the link.
+func (u *URL) IsProtected() bool {
+	return u.PasswordHash != nil
+}
+
+// IsPermanent reports whether the link redirects with 301 or 308, which
+// browsers and crawlers may remember.
+func (u *URL) IsPermanent() bool {
+	return u.RedirectType == http.StatusMovedPermanently || u.RedirectType == http.StatusPermanentRedirect
+}
+
+// IsExpired reports whether the link's expiry time has passed.
+func (u *URL) IsExpired(now time.Time) bool {
+	return u.ExpiresAt != nil && !now.Before(*u.ExpiresAt)
+}
+
+// IsExhausted reports whether the link has used up its click allowance.
+func (u *URL) IsExhausted() bool {
+	return u.MaxClicks != nil && u.ClickCount >= *u.MaxClicks
 }
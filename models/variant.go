@@ -0,0 +1,52 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// Variant is one destination of an A/B split, served to a share of visitors
+// proportional to its Weight. Name identifies it in click events and
+// analytics.
+type Variant struct {
+	Name        string `json:"name"`
+	Destination string `json:"destination"`
+	Weight      int    `json:"weight"`
+}
+
+// Variants is stored as JSONB.
+type Variants []Variant
+
+func (v Variants) Value() (driver.Value, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+func (v *Variants) Scan(value interface{}) error {
+	var raw []byte
+	switch val := value.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case []byte:
+		raw = val
+	case string:
+		raw = []byte(val)
+	default:
+		return errors.New("unsupported type for variants")
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// Find returns the variant with the given name.
+func (v Variants) Find(name string) (Variant, bool) {
+	for _, variant := range v {
+		if variant.Name == name {
+			return variant, true
+		}
+	}
+	return Variant{}, false
+}
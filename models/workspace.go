@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+type Workspace struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Name      string    `json:"name"`
+	CreatedBy uuid.UUID `json:"created_by"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+type WorkspaceMember struct {
+	WorkspaceID uuid.UUID `gorm:"primaryKey" json:"workspace_id"`
+	MemberID    uuid.UUID `gorm:"primaryKey" json:"member_id"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// WorkspaceWithRole is a workspace as seen by one of its members.
+type WorkspaceWithRole struct {
+	Workspace
+	Role string `json:"role"`
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mohan7-code/url-shortener/models"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type IAPIKeyRepository interface {
+	Create(ctx *context.Context, key *models.APIKey) error
+	GetActiveByHash(ctx *context.Context, keyHash string) (*models.APIKey, error)
+	Revoke(ctx *context.Context, id, ownerID string) (*models.APIKey, error)
+}
+
+type apiKeyRepository struct {
+}
+
+func NewAPIKeyRepository() IAPIKeyRepository {
+	return &apiKeyRepository{}
+}
+
+func (r *apiKeyRepository) getTable() string {
+	return "api_keys"
+}
+
+func (r *apiKeyRepository) Create(ctx *context.Context, key *models.APIKey) error {
+	err := ctx.DB.WithContext(ctx).Table(r.getTable()).Create(key).Error
+	if err != nil {
+		ctx.Log.Error("failed to create api key", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) GetActiveByHash(ctx *context.Context, keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := ctx.DB.WithContext(ctx).Table(r.getTable()).
+		Where("key_hash = ? AND revoked_at IS NULL", keyHash).
+		First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		ctx.Log.Error("failed to get api key", zap.Error(err))
+		return nil, err
+	}
+	return &key, nil
+}
+
+// Revoke marks the owner's key as revoked and returns it, or nil when the
+// owner has no such active key.
+func (r *apiKeyRepository) Revoke(ctx *context.Context, id, ownerID string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := ctx.DB.WithContext(ctx).Table(r.getTable()).
+		Where("id = ? AND owner_id = ? AND revoked_at IS NULL", id, ownerID).
+		First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		ctx.Log.Error("failed to get api key for revoke", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	now := time.Now()
+	err = ctx.DB.WithContext(ctx).Table(r.getTable()).
+		Where("id = ?", id).
+		Update("revoked_at", now).Error
+	if err != nil {
+		ctx.Log.Error("failed to revoke api key", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+
+	key.RevokedAt = &now
+	return &key, nil
+}
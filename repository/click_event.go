@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mohan7-code/url-shortener/dtos"
+	"github.com/mohan7-code/url-shortener/models"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const clickInsertBatchSize = 500
+
+// Dimensions click events can be broken down by.
+const (
+	DimensionReferrer = "referrer_host"
+	DimensionBrowser  = "browser"
+	DimensionOS       = "os"
+	DimensionCountry  = "country"
+	DimensionVariant  = "variant"
+)
+
+// dimensionFallbacks is also the whitelist of columns allowed in breakdowns.
+var dimensionFallbacks = map[string]string{
+	DimensionReferrer: "direct",
+	DimensionBrowser:  "unknown",
+	DimensionOS:       "unknown",
+	DimensionCountry:  "unknown",
+	DimensionVariant:  "none",
+}
+
+type IClickEventRepository interface {
+	RecordClick(ctx *context.Context, event *models.ClickEvent) error
+	ClaimClick(ctx *context.Context, event *models.ClickEvent) (bool, error)
+	RecordClicks(ctx *context.Context, events []*models.ClickEvent) error
+	ListByURL(ctx *context.Context, urlID string, from, to time.Time, limit int) ([]*models.ClickEvent, error)
+	CountByURL(ctx *context.Context, urlID string, from, to time.Time) (int64, error)
+	ClickTimeSeries(ctx *context.Context, urlID string, from, to time.Time, interval string) ([]dtos.TimeBucket, error)
+	ClickBreakdown(ctx *context.Context, urlID string, dimension string, from, to time.Time, limit int) ([]dtos.BreakdownItem, error)
+}
+
+type clickEventRepository struct {
+}
+
+func NewClickEventRepository() IClickEventRepository {
+	return &clickEventRepository{}
+}
+
+func (r *clickEventRepository) getTable() string {
+	return clickEventTable
+}
+
+// RecordClick stores the event and bumps the link's counters in the same
+// transaction so click_count always matches the number of events. The event
+// is dropped if the link has been deleted since the click happened.
+func (r *clickEventRepository) RecordClick(ctx *context.Context, event *models.ClickEvent) error {
+	err := ctx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Table(urlTable).
+			Where("id = ?", event.URLID).
+			Updates(map[string]interface{}{
+				"click_count":      gorm.Expr("click_count + ?", 1),
+				"last_accessed_at": event.ClickedAt,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		return tx.Table(r.getTable()).Create(event).Error
+	})
+
+	if err != nil {
+		ctx.Log.Error("failed to record click", zap.String("url_id", event.URLID.String()), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ClaimClick counts the click against the link's max_clicks and stores the
+// event in one transaction. The counter is only bumped while it is below the
+// limit, so concurrent redirects cannot overshoot it; false means the link
+// had no clicks left and nothing was recorded.
+func (r *clickEventRepository) ClaimClick(ctx *context.Context, event *models.ClickEvent) (bool, error) {
+	claimed := false
+	err := ctx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Table(urlTable).
+			Where("id = ?", event.URLID).
+			Where("(max_clicks IS NULL OR click_count < max_clicks)").
+			Updates(map[string]interface{}{
+				"click_count":      gorm.Expr("click_count + ?", 1),
+				"last_accessed_at": event.ClickedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		claimed = true
+		return tx.Table(r.getTable()).Create(event).Error
+	})
+
+	if err != nil {
+		ctx.Log.Error("failed to claim click", zap.String("url_id", event.URLID.String()), zap.Error(err))
+		return false, err
+	}
+	return claimed, nil
+}
+
+// RecordClicks stores a batch of events and applies one aggregated counter
+// update per link, all in a single transaction. Events for links deleted
+// since the click happened are dropped.
+func (r *clickEventRepository) RecordClicks(ctx *context.Context, events []*models.ClickEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	err := ctx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		type linkClicks struct {
+			count      int64
+			lastAccess time.Time
+		}
+
+		perLink := make(map[uuid.UUID]*linkClicks)
+		for _, event := range events {
+			agg, ok := perLink[event.URLID]
+			if !ok {
+				agg = &linkClicks{}
+				perLink[event.URLID] = agg
+			}
+			agg.count++
+			if event.ClickedAt.After(agg.lastAccess) {
+				agg.lastAccess = event.ClickedAt
+			}
+		}
+
+		ids := make([]uuid.UUID, 0, len(perLink))
+		for id := range perLink {
+			ids = append(ids, id)
+		}
+
+		// lock rows in a stable order so concurrent flushes cannot deadlock
+		var existing []uuid.UUID
+		if err := tx.Table(urlTable).Where("id IN ?", ids).Order("id").
+			Clauses(clause.Locking{Strength: "UPDATE"}).Pluck("id", &existing).Error; err != nil {
+			return err
+		}
+
+		live := make(map[uuid.UUID]bool, len(existing))
+		for _, id := range existing {
+			live[id] = true
+		}
+
+		kept := make([]*models.ClickEvent, 0, len(events))
+		for _, event := range events {
+			if live[event.URLID] {
+				kept = append(kept, event)
+			}
+		}
+		if len(kept) == 0 {
+			return nil
+		}
+
+		if err := tx.Table(r.getTable()).CreateInBatches(kept, clickInsertBatchSize).Error; err != nil {
+			return err
+		}
+
+		values := make([]string, 0, len(existing))
+		args := make([]interface{}, 0, len(existing)*3)
+		for _, id := range existing {
+			agg := perLink[id]
+			values = append(values, "(?::uuid, ?::bigint, ?::timestamptz)")
+			args = append(args, id, agg.count, agg.lastAccess)
+		}
+
+		return tx.Exec(`UPDATE `+urlTable+` AS u
+			SET click_count = u.click_count + v.clicks,
+				last_accessed_at = GREATEST(u.last_accessed_at, v.last_accessed_at)
+			FROM (VALUES `+strings.Join(values, ", ")+`) AS v(id, clicks, last_accessed_at)
+			WHERE u.id = v.id`, args...).Error
+	})
+
+	if err != nil {
+		ctx.Log.Error("failed to record click batch", zap.Int("events", len(events)), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *clickEventRepository) ListByURL(ctx *context.Context, urlID string, from, to time.Time, limit int) ([]*models.ClickEvent, error) {
+	var events []*models.ClickEvent
+
+	query := ctx.DB.WithContext(ctx).Table(r.getTable()).
+		Where("url_id = ? AND clicked_at >= ? AND clicked_at < ?", urlID, from, to).
+		Order("clicked_at DESC")
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	if err := query.Find(&events).Error; err != nil {
+		ctx.Log.Error("failed to list click events", zap.String("url_id", urlID), zap.Error(err))
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *clickEventRepository) CountByURL(ctx *context.Context, urlID string, from, to time.Time) (int64, error) {
+	var total int64
+
+	err := ctx.DB.WithContext(ctx).Table(r.getTable()).
+		Where("url_id = ? AND clicked_at >= ? AND clicked_at < ?", urlID, from, to).
+		Count(&total).Error
+
+	if err != nil {
+		ctx.Log.Error("failed to count click events", zap.String("url_id", urlID), zap.Error(err))
+		return 0, err
+	}
+	return total, nil
+}
+
+// ClickTimeSeries counts clicks per UTC bucket. interval must be a Postgres
+// date_trunc field (hour, day, week or month). Empty buckets are not returned.
+func (r *clickEventRepository) ClickTimeSeries(ctx *context.Context, urlID string, from, to time.Time, interval string) ([]dtos.TimeBucket, error) {
+	var buckets []dtos.TimeBucket
+
+	err := ctx.DB.WithContext(ctx).Table(r.getTable()).
+		Select("date_trunc(?, clicked_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS start, COUNT(*) AS clicks", interval).
+		Where("url_id = ? AND clicked_at >= ? AND clicked_at < ?", urlID, from, to).
+		Group("1").
+		Order("1").
+		Scan(&buckets).Error
+
+	if err != nil {
+		ctx.Log.Error("failed to build click time series", zap.String("url_id", urlID), zap.Error(err))
+		return nil, err
+	}
+	return buckets, nil
+}
+
+func (r *clickEventRepository) ClickBreakdown(ctx *context.Context, urlID string, dimension string, from, to time.Time, limit int) ([]dtos.BreakdownItem, error) {
+	fallback, ok := dimensionFallbacks[dimension]
+	if !ok {
+		return nil, fmt.Errorf("unknown click dimension %q", dimension)
+	}
+
+	var items []dtos.BreakdownItem
+
+	err := ctx.DB.WithContext(ctx).Table(r.getTable()).
+		Select(fmt.Sprintf("COALESCE(NULLIF(%s, ''), ?) AS value, COUNT(*) AS clicks", dimension), fallback).
+		Where("url_id = ? AND clicked_at >= ? AND clicked_at < ?", urlID, from, to).
+		Group("1").
+		Order("clicks DESC").
+		Limit(limit).
+		Scan(&items).Error
+
+	if err != nil {
+		ctx.Log.Error("failed to build click breakdown", zap.String("url_id", urlID), zap.String("dimension", dimension), zap.Error(err))
+		return nil, err
+	}
+	return items, nil
+}
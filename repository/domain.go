@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/mohan7-code/url-shortener/models"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type IDomainRepository interface {
+	Create(ctx *context.Context, domain *models.Domain) error
+	GetByHostname(ctx *context.Context, hostname string) (*models.Domain, error)
+	GetByID(ctx *context.Context, id string) (*models.Domain, error)
+	ListByWorkspace(ctx *context.Context, workspaceID string) ([]*models.Domain, error)
+	Delete(ctx *context.Context, id string) error
+	CountLinks(ctx *context.Context, hostname string) (int64, error)
+}
+
+type domainRepository struct {
+}
+
+func NewDomainRepository() IDomainRepository {
+	return &domainRepository{}
+}
+
+func (r *domainRepository) getTable() string {
+	return "domains"
+}
+
+func (r *domainRepository) Create(ctx *context.Context, domain *models.Domain) error {
+	err := ctx.DB.WithContext(ctx).Table(r.getTable()).Create(domain).Error
+	if err != nil {
+		ctx.Log.Error("failed to create domain", zap.String("hostname", domain.Hostname), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *domainRepository) GetByHostname(ctx *context.Context, hostname string) (*models.Domain, error) {
+	return r.getBy(ctx, "hostname = ?", hostname)
+}
+
+func (r *domainRepository) GetByID(ctx *context.Context, id string) (*models.Domain, error) {
+	return r.getBy(ctx, "id = ?", id)
+}
+
+func (r *domainRepository) getBy(ctx *context.Context, condition string, value string) (*models.Domain, error) {
+	var domain models.Domain
+	err := ctx.DB.WithContext(ctx).Table(r.getTable()).Where(condition, value).First(&domain).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		ctx.Log.Error("failed to get domain", zap.String("value", value), zap.Error(err))
+		return nil, err
+	}
+	return &domain, nil
+}
+
+func (r *domainRepository) ListByWorkspace(ctx *context.Context, workspaceID string) ([]*models.Domain, error) {
+	var domains []*models.Domain
+
+	err := ctx.DB.WithContext(ctx).Table(r.getTable()).
+		Where("workspace_id = ?", workspaceID).
+		Order("created_at").
+		Find(&domains).Error
+
+	if err != nil {
+		ctx.Log.Error("failed to list domains", zap.String("workspace_id", workspaceID), zap.Error(err))
+		return nil, err
+	}
+	return domains, nil
+}
+
+func (r *domainRepository) Delete(ctx *context.Context, id string) error {
+	err := ctx.DB.WithContext(ctx).Table(r.getTable()).
+		Where("id = ?", id).
+		Delete(&models.Domain{}).Error
+
+	if err != nil {
+		ctx.Log.Error("failed to delete domain", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *domainRepository) CountLinks(ctx *context.Context, hostname string) (int64, error) {
+	var total int64
+
+	err := ctx.DB.WithContext(ctx).Table(urlTable).
+		Where("domain = ?", hostname).
+		Count(&total).Error
+
+	if err != nil {
+		ctx.Log.Error("failed to count domain links", zap.String("hostname", hostname), zap.Error(err))
+		return 0, err
+	}
+	return total, nil
+}
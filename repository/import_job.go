@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mohan7-code/url-shortener/models"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type IImportJobRepository interface {
+	Create(ctx *context.Context, job *models.ImportJob) error
+	GetByID(ctx *context.Context, id string) (*models.ImportJob, error)
+	UpdateProgress(ctx *context.Context, job *models.ImportJob) error
+	Finish(ctx *context.Context, job *models.ImportJob) error
+}
+
+type importJobRepository struct {
+}
+
+func NewImportJobRepository() IImportJobRepository {
+	return &importJobRepository{}
+}
+
+func (r *importJobRepository) getTable() string {
+	return "import_jobs"
+}
+
+func (r *importJobRepository) Create(ctx *context.Context, job *models.ImportJob) error {
+	err := ctx.DB.WithContext(ctx).Table(r.getTable()).Create(job).Error
+	if err != nil {
+		ctx.Log.Error("failed to create import job", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *importJobRepository) GetByID(ctx *context.Context, id string) (*models.ImportJob, error) {
+	var job models.ImportJob
+	err := ctx.DB.WithContext(ctx).Table(r.getTable()).Where("id = ?", id).First(&job).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		ctx.Log.Error("failed to get import job", zap.String("id", id), zap.Error(err))
+		return nil, err
+	}
+	return &job, nil
+}
+
+// UpdateProgress saves the job's status and row counters.
+func (r *importJobRepository) UpdateProgress(ctx *context.Context, job *models.ImportJob) error {
+	err := ctx.DB.WithContext(ctx).Table(r.getTable()).
+		Where("id = ?", job.ID).
+		Updates(map[string]interface{}{
+			"status":         job.Status,
+			"total_rows":     job.TotalRows,
+			"succeeded_rows": job.SucceededRows,
+			"failed_rows":    job.FailedRows,
+		}).Error
+
+	if err != nil {
+		ctx.Log.Error("failed to update import job", zap.String("id", job.ID.String()), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Finish saves the job's final status, counters and error report.
+func (r *importJobRepository) Finish(ctx *context.Context, job *models.ImportJob) error {
+	now := time.Now()
+	err := ctx.DB.WithContext(ctx).Table(r.getTable()).
+		Where("id = ?", job.ID).
+		Updates(map[string]interface{}{
+			"status":         job.Status,
+			"total_rows":     job.TotalRows,
+			"succeeded_rows": job.SucceededRows,
+			"failed_rows":    job.FailedRows,
+			"error":          job.Error,
+			"error_report":   job.ErrorReport,
+			"finished_at":    now,
+		}).Error
+
+	if err != nil {
+		ctx.Log.Error("failed to finish import job", zap.String("id", job.ID.String()), zap.Error(err))
+		return err
+	}
+	job.FinishedAt = &now
+	return nil
+}
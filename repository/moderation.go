@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/mohan7-code/url-shortener/models"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type IModerationRepository interface {
+	CreateReport(ctx *context.Context, report *models.LinkReport) error
+	HasOpenReport(ctx *context.Context, urlID, reporterIPHash string) (bool, error)
+	CountOpenReports(ctx *context.Context, urlID string) (int64, error)
+	ListReports(ctx *context.Context, open bool, limit, offset int) ([]*models.ReportWithLink, int64, error)
+	ApplyStatus(ctx *context.Context, action *models.ModerationAction, resolveReports bool) (bool, error)
+	ListActions(ctx *context.Context, urlID string) ([]*models.ModerationAction, error)
+	ListActionsByCode(ctx *context.Context, domain, shortCode string) ([]*models.ModerationAction, error)
+}
+
+type moderationRepository struct {
+}
+
+func NewModerationRepository() IModerationRepository {
+	return &moderationRepository{}
+}
+
+func (r *moderationRepository) getReportsTable() string {
+	return "link_reports"
+}
+
+func (r *moderationRepository) getActionsTable() string {
+	return "moderation_actions"
+}
+
+func (r *moderationRepository) CreateReport(ctx *context.Context, report *models.LinkReport) error {
+	err := ctx.DB.WithContext(ctx).Table(r.getReportsTable()).Create(report).Error
+	if err != nil {
+		ctx.Log.Error("failed to create link report", zap.String("url_id", report.URLID.String()), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// HasOpenReport reports whether the same reporter already has an unresolved
+// report against the link.
+func (r *moderationRepository) HasOpenReport(ctx *context.Context, urlID, reporterIPHash string) (bool, error) {
+	var total int64
+
+	err := ctx.DB.WithContext(ctx).Table(r.getReportsTable()).
+		Where("url_id = ? AND reporter_ip_hash = ? AND resolved_at IS NULL", urlID, reporterIPHash).
+		Count(&total).Error
+
+	if err != nil {
+		ctx.Log.Error("failed to check open reports", zap.String("url_id", urlID), zap.Error(err))
+		return false, err
+	}
+	return total > 0, nil
+}
+
+func (r *moderationRepository) CountOpenReports(ctx *context.Context, urlID string) (int64, error) {
+	var total int64
+
+	err := ctx.DB.WithContext(ctx).Table(r.getReportsTable()).
+		Where("url_id = ? AND resolved_at IS NULL", urlID).
+		Count(&total).Error
+
+	if err != nil {
+		ctx.Log.Error("failed to count open reports", zap.String("url_id", urlID), zap.Error(err))
+		return 0, err
+	}
+	return total, nil
+}
+
+// ListReports returns open or resolved reports with the reported link's
+// fields, newest first. Reports outlive their links: an archived link's
+// fields come from the archive and its status reads "archived", while a
+// deleted link leaves only its status, "deleted".
+func (r *moderationRepository) ListReports(ctx *context.Context, open bool, limit, offset int) ([]*models.ReportWithLink, int64, error) {
+	var reports []*models.ReportWithLink
+	var total int64
+
+	query := ctx.DB.WithContext(ctx).Table(r.getReportsTable() + " AS r").
+		Joins("LEFT JOIN " + urlTable + " AS u ON u.id = r.url_id").
+		Joins("LEFT JOIN " + archiveTable + " AS a ON a.id = r.url_id")
+	if open {
+		query = query.Where("r.resolved_at IS NULL")
+	} else {
+		query = query.Where("r.resolved_at IS NOT NULL")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		ctx.Log.Error("failed to count link reports", zap.Error(err))
+		return nil, 0, err
+	}
+
+	if limit > 0 {
+		query = query.Limit(limit).Offset(offset)
+	}
+
+	err := query.Select(`r.*,
+		COALESCE(u.short_code, a.short_code, '') AS short_code,
+		COALESCE(u.domain, a.data->>'domain') AS domain,
+		COALESCE(u.original_url, a.original_url, '') AS original_url,
+		CASE WHEN u.id IS NOT NULL THEN u.status WHEN a.id IS NOT NULL THEN 'archived' ELSE 'deleted' END AS status`).
+		Order("r.created_at DESC").
+		Find(&reports).Error
+	if err != nil {
+		ctx.Log.Error("failed to list link reports", zap.Error(err))
+		return nil, 0, err
+	}
+
+	return reports, total, nil
+}
+
+// ApplyStatus moves the link from action.FromStatus to action.ToStatus and
+// records the action in one transaction, resolving the link's open reports
+// when asked. It returns false without writing anything when the link is no
+// longer in FromStatus.
+func (r *moderationRepository) ApplyStatus(ctx *context.Context, action *models.ModerationAction, resolveReports bool) (bool, error) {
+	applied := false
+
+	err := ctx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Table(urlTable).
+			Where("id = ? AND status = ?", action.URLID, action.FromStatus).
+			Update("status", action.ToStatus)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Table(r.getActionsTable()).Create(action).Error; err != nil {
+			return err
+		}
+
+		if resolveReports {
+			err := tx.Table(r.getReportsTable()).
+				Where("url_id = ? AND resolved_at IS NULL", action.URLID).
+				Updates(map[string]interface{}{"resolved_at": time.Now(), "resolution": action.ToStatus}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		applied = true
+		return nil
+	})
+
+	if err != nil {
+		ctx.Log.Error("failed to apply moderation status", zap.String("url_id", action.URLID.String()), zap.Error(err))
+		return false, err
+	}
+	return applied, nil
+}
+
+func (r *moderationRepository) ListActions(ctx *context.Context, urlID string) ([]*models.ModerationAction, error) {
+	var actions []*models.ModerationAction
+
+	err := ctx.DB.WithContext(ctx).Table(r.getActionsTable()).
+		Where("url_id = ?", urlID).
+		Order("created_at").
+		Find(&actions).Error
+
+	if err != nil {
+		ctx.Log.Error("failed to list moderation actions", zap.String("url_id", urlID), zap.Error(err))
+		return nil, err
+	}
+	return actions, nil
+}
+
+// ListActionsByCode returns the audit trail of every link that has had the
+// code on the domain, including links since archived or deleted.
+func (r *moderationRepository) ListActionsByCode(ctx *context.Context, domain, shortCode string) ([]*models.ModerationAction, error) {
+	var actions []*models.ModerationAction
+
+	query := ctx.DB.WithContext(ctx).Table(r.getActionsTable()).Where("short_code = ?", shortCode)
+	if domain == "" {
+		query = query.Where("domain IS NULL")
+	} else {
+		query = query.Where("domain = ?", domain)
+	}
+
+	err := query.Order("created_at").Find(&actions).Error
+	if err != nil {
+		ctx.Log.Error("failed to list moderation actions", zap.String("short_code", shortCode), zap.Error(err))
+		return nil, err
+	}
+	return actions, nil
+}
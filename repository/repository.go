@@ -2,21 +2,64 @@ package repository
 
 import (
 	"errors"
+	"net/http"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/mohan7-code/url-shortener/models"
 	context "github.com/mohan7-code/url-shortener/utils/context"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+const (
+	urlTable        = "url_shortner"
+	archiveTable    = "url_shortner_archive"
+	clickEventTable = "click_events"
+
+	// createBatchSize keeps each INSERT of CreateMany well under Postgres'
+	// limit on bind parameters.
+	createBatchSize = 200
+
+	// exportBatchSize is how many links EachURL holds in memory at once.
+	exportBatchSize = 500
+)
+
+// ErrDuplicateCode is returned when a link's short code is already taken on
+// its domain, for example by a link created since the code was checked.
+var ErrDuplicateCode = errors.New("short code already exists")
+
 type IURLRepository interface {
 	Create(ctx *context.Context, url *models.URL) error
-	GetUrlByShortCode(ctx *context.Context, shortCode string) (*models.URL, error)
-	GetByOriginalURL(ctx *context.Context, originalURL string) (*models.URL, error)
-	IncrementClickCount(ctx *context.Context, id string) error
-	IncrementClickCountByShortCode(ctx *context.Context, code string) error
-	ListURLs(ctx *context.Context, limit, offset int) ([]*models.URL, int64, error)
+	CreateMany(ctx *context.Context, urls []*models.URL) ([]error, error)
+	GetUrlByShortCode(ctx *context.Context, domain, shortCode string) (*models.URL, error)
+	GetLiveByOriginalURL(ctx *context.Context, scope URLScope, domain, originalURL string) (*models.URL, error)
+	NextCodeSequence(ctx *context.Context) (int64, error)
+	ListURLs(ctx *context.Context, scope URLScope, filter URLFilter, page KeysetPage) ([]*models.URL, error)
+	EachURL(ctx *context.Context, scope URLScope, fn func(*models.URL) error) error
+	UpdateOriginalURL(ctx *context.Context, id string, originalURL string) error
+	UpdateTargeting(ctx *context.Context, id string, targeting models.TargetingRules) error
+	UpdateVariants(ctx *context.Context, id string, variants models.Variants) error
+	UpdateRedirectType(ctx *context.Context, id string, redirectType int) error
+	UpdateTags(ctx *context.Context, id string, tags models.Tags) error
+	UpdateTitle(ctx *context.Context, id string, title string) error
+	SearchURLs(ctx *context.Context, scope URLScope, q string, limit int) ([]*models.SearchHit, error)
+	DeleteByID(ctx *context.Context, id string) error
+	ArchiveExpired(ctx *context.Context, cutoff time.Time) (int64, error)
+}
+
+// URLScope selects whose links a query sees: a workspace's links when
+// WorkspaceID is set, otherwise the owner's personal links.
+type URLScope struct {
+	OwnerID     uuid.UUID
+	WorkspaceID uuid.UUID
+}
+
+func (sc URLScope) apply(query *gorm.DB) *gorm.DB {
+	if sc.WorkspaceID != uuid.Nil {
+		return query.Where("workspace_id = ?", sc.WorkspaceID)
+	}
+	return query.Where("owner_id = ? AND workspace_id IS NULL", sc.OwnerID)
 }
 
 type urlRepository struct {
@@ -27,21 +70,94 @@ func NewURLRepository() IURLRepository {
 }
 
 func (r *urlRepository) getTable() string {
-	return "url_shortner"
+	return urlTable
+}
+
+func (r *urlRepository) getArchiveTable() string {
+	return archiveTable
 }
 
 func (r *urlRepository) Create(ctx *context.Context, url *models.URL) error {
-	err := ctx.DB.WithContext(ctx).Table(r.getTable()).Save(url).Error
+	db := ctx.DB.WithContext(ctx)
+	err := db.Table(r.getTable()).Save(url).Error
 	if err != nil {
+		if isDuplicateKey(db, err) {
+			return ErrDuplicateCode
+		}
 		ctx.Log.Error("failed to create short url", zap.Error(err))
 		return err
 	}
 	return nil
 }
 
-func (r *urlRepository) GetUrlByShortCode(ctx *context.Context, shortCode string) (*models.URL, error) {
+// CreateMany inserts the links in a single transaction and returns the error
+// of each link that could not be saved, indexed like urls. The links are
+// inserted in batches; if a batch fails they are inserted again one by one,
+// each under its own savepoint, so a bad row only fails itself. The second
+// error is set when the transaction itself fails and nothing was saved.
+func (r *urlRepository) CreateMany(ctx *context.Context, urls []*models.URL) ([]error, error) {
+	errs := make([]error, len(urls))
+
+	err := ctx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.SavePoint("batch").Error; err != nil {
+			return err
+		}
+		if err := tx.Table(r.getTable()).CreateInBatches(urls, createBatchSize).Error; err == nil {
+			return nil
+		}
+		if err := tx.RollbackTo("batch").Error; err != nil {
+			return err
+		}
+
+		for i, url := range urls {
+			if err := tx.SavePoint("link").Error; err != nil {
+				return err
+			}
+			err := tx.Table(r.getTable()).Create(url).Error
+			if err == nil {
+				continue
+			}
+
+			if isDuplicateKey(tx, err) {
+				errs[i] = ErrDuplicateCode
+			} else {
+				ctx.Log.Error("failed to create short url", zap.String("short_code", url.ShortCode), zap.Error(err))
+				errs[i] = err
+			}
+			if err := tx.RollbackTo("link").Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		ctx.Log.Error("failed to create short urls", zap.Int("count", len(urls)), zap.Error(err))
+		return nil, err
+	}
+	return errs, nil
+}
+
+// isDuplicateKey reports whether err is a unique constraint violation.
+func isDuplicateKey(db *gorm.DB, err error) bool {
+	if translator, ok := db.Dialector.(gorm.ErrorTranslator); ok {
+		err = translator.Translate(err)
+	}
+	return errors.Is(err, gorm.ErrDuplicatedKey)
+}
+
+// GetUrlByShortCode looks a code up on a branded domain, or on the default
+// domain when domain is empty.
+func (r *urlRepository) GetUrlByShortCode(ctx *context.Context, domain, shortCode string) (*models.URL, error) {
 	var url models.URL
-	err := ctx.DB.Debug().WithContext(ctx).Table(r.getTable()).Where("short_code = ?", shortCode).First(&url).Error
+
+	query := ctx.DB.Debug().WithContext(ctx).Table(r.getTable()).Where("short_code = ?", shortCode)
+	if domain == "" {
+		query = query.Where("domain IS NULL")
+	} else {
+		query = query.Where("domain = ?", domain)
+	}
+
+	err := query.First(&url).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			ctx.Log.Warn("short code not found", zap.Any("short_code", shortCode))
@@ -53,67 +169,221 @@ func (r *urlRepository) GetUrlByShortCode(ctx *context.Context, shortCode string
 	return &url, nil
 }
 
-func (r *urlRepository) GetByOriginalURL(ctx *context.Context, originalURL string) (*models.URL, error) {
+// GetLiveByOriginalURL returns the newest active link in the scope that
+// points to originalURL on the domain and has none of the settings that make
+// a link specific: an expiry, a click limit, a password, targeting rules,
+// variants, tags, a title or a non-default redirect type.
+func (r *urlRepository) GetLiveByOriginalURL(ctx *context.Context, scope URLScope, domain, originalURL string) (*models.URL, error) {
 	var url models.URL
-	err := ctx.DB.Table(r.getTable()).Where("original_url = ?", originalURL).First(&url).Error
-	if err != nil && err != gorm.ErrRecordNotFound {
+
+	query := scope.apply(ctx.DB.WithContext(ctx).Table(r.getTable())).
+		Where("original_url = ?", originalURL).
+		Where("status = ?", models.StatusActive).
+		Where("password_hash IS NULL AND targeting IS NULL AND variants IS NULL AND tags IS NULL AND title = ''").
+		Where("redirect_type = ?", http.StatusFound).
+		Where("expires_at IS NULL AND max_clicks IS NULL")
+	if domain == "" {
+		query = query.Where("domain IS NULL")
+	} else {
+		query = query.Where("domain = ?", domain)
+	}
+
+	err := query.Order("created_at DESC").First(&url).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
 		ctx.Log.Error("failed to find by original url", zap.String("original_url", originalURL), zap.Error(err))
 		return nil, err
 	}
 	return &url, nil
 }
 
-func (r *urlRepository) IncrementClickCount(ctx *context.Context, id string) error {
+// NextCodeSequence returns the next value of the sequence behind generated
+// short codes.
+func (r *urlRepository) NextCodeSequence(ctx *context.Context) (int64, error) {
+	var n int64
+	err := ctx.DB.WithContext(ctx).Raw("SELECT nextval('url_short_code_seq')").Scan(&n).Error
+	if err != nil {
+		ctx.Log.Error("failed to read short code sequence", zap.Error(err))
+		return 0, err
+	}
+	return n, nil
+}
+
+// ListURLs returns up to page.Limit links of the scope that match the
+// filter, in the page's order. Links are returned in the order they are
+// read, so a backward page comes out reversed.
+func (r *urlRepository) ListURLs(ctx *context.Context, scope URLScope, filter URLFilter, page KeysetPage) ([]*models.URL, error) {
+	var urls []*models.URL
+
+	query := filter.apply(scope.apply(ctx.DB.WithContext(ctx).Table(r.getTable())))
+	query = page.apply(query)
+
+	if err := query.Find(&urls).Error; err != nil {
+		ctx.Log.Error("failed to list urls", zap.Error(err))
+		return nil, err
+	}
+	return urls, nil
+}
+
+// SearchURLs returns up to limit links in the scope whose short code, title,
+// tags or destination match q, best match first. Words are matched through
+// search_vector and fragments through the trigram indexes; each hit's title
+// headline marks the matched words.
+func (r *urlRepository) SearchURLs(ctx *context.Context, scope URLScope, q string, limit int) ([]*models.SearchHit, error) {
+	var hits []*models.SearchHit
+
+	like := "%" + escapeLike(q) + "%"
+	query := scope.apply(ctx.DB.WithContext(ctx).Table(r.getTable()+", websearch_to_tsquery('simple', ?) query", q)).
+		Select(r.getTable()+".*, "+
+			"ts_rank(search_vector, query) + greatest(similarity(short_code, ?), word_similarity(?, title), word_similarity(?, original_url)) AS rank, "+
+			"ts_headline('simple', title, query, ?) AS title_headline",
+			q, q, q, headlineOptions).
+		Where(`(search_vector @@ query OR short_code ILIKE ? ESCAPE '\' OR title ILIKE ? ESCAPE '\' OR original_url ILIKE ? ESCAPE '\')`,
+			like, like, like).
+		Order("rank DESC, created_at DESC").
+		Limit(limit)
+
+	if err := query.Find(&hits).Error; err != nil {
+		ctx.Log.Error("failed to search urls", zap.String("q", q), zap.Error(err))
+		return nil, err
+	}
+	return hits, nil
+}
+
+// EachURL calls fn for every link in the scope, loading them
+// exportBatchSize at a time in primary key order so memory stays flat however
+// many links there are. It stops at the first error fn returns.
+func (r *urlRepository) EachURL(ctx *context.Context, scope URLScope, fn func(*models.URL) error) error {
+	var batch []*models.URL
+
+	err := scope.apply(ctx.DB.WithContext(ctx).Table(r.getTable())).
+		FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+			for _, url := range batch {
+				if err := fn(url); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+
+	if err != nil {
+		ctx.Log.Error("failed to iterate urls", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *urlRepository) UpdateOriginalURL(ctx *context.Context, id string, originalURL string) error {
 	err := ctx.DB.WithContext(ctx).Table(r.getTable()).
 		Where("id = ?", id).
-		Updates(map[string]interface{}{
-			"click_count":      gorm.Expr("click_count + ?", 1),
-			"last_accessed_at": time.Now(),
-		}).Error
+		Update("original_url", originalURL).Error
 
 	if err != nil {
-		ctx.Log.Error("failed to increment click count", zap.String("id", id), zap.Error(err))
+		ctx.Log.Error("failed to update original url", zap.String("id", id), zap.Error(err))
 		return err
 	}
 	return nil
 }
 
-func (r *urlRepository) IncrementClickCountByShortCode(ctx *context.Context, code string) error {
+func (r *urlRepository) UpdateTargeting(ctx *context.Context, id string, targeting models.TargetingRules) error {
+	err := ctx.DB.WithContext(ctx).Table(r.getTable()).
+		Where("id = ?", id).
+		Update("targeting", targeting).Error
 
+	if err != nil {
+		ctx.Log.Error("failed to update targeting", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *urlRepository) UpdateVariants(ctx *context.Context, id string, variants models.Variants) error {
 	err := ctx.DB.WithContext(ctx).Table(r.getTable()).
-		Where("short_code = ?", code).
-		Updates(map[string]interface{}{
-			"click_count":      gorm.Expr("click_count + ?", 1),
-			"last_accessed_at": time.Now(),
-		}).Error
+		Where("id = ?", id).
+		Update("variants", variants).Error
 
 	if err != nil {
-		ctx.Log.Error("failed to increment click count", zap.String("code", code), zap.Error(err))
+		ctx.Log.Error("failed to update variants", zap.String("id", id), zap.Error(err))
 		return err
 	}
 	return nil
 }
 
-func (r *urlRepository) ListURLs(ctx *context.Context, limit, offset int) ([]*models.URL, int64, error) {
-	var urls []*models.URL
-	var total int64
+func (r *urlRepository) UpdateRedirectType(ctx *context.Context, id string, redirectType int) error {
+	err := ctx.DB.WithContext(ctx).Table(r.getTable()).
+		Where("id = ?", id).
+		Update("redirect_type", redirectType).Error
+
+	if err != nil {
+		ctx.Log.Error("failed to update redirect type", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
 
-	query := ctx.DB.WithContext(ctx).Table(r.getTable())
+func (r *urlRepository) UpdateTags(ctx *context.Context, id string, tags models.Tags) error {
+	err := ctx.DB.WithContext(ctx).Table(r.getTable()).
+		Where("id = ?", id).
+		Update("tags", tags).Error
 
-	if err := query.Count(&total).Error; err != nil {
-		ctx.Log.Error("failed to count urls", zap.Error(err))
-		return nil, 0, err
+	if err != nil {
+		ctx.Log.Error("failed to update tags", zap.String("id", id), zap.Error(err))
+		return err
 	}
+	return nil
+}
 
-	if limit > 0 {
-		query = query.Limit(limit).Offset(offset)
+func (r *urlRepository) UpdateTitle(ctx *context.Context, id string, title string) error {
+	err := ctx.DB.WithContext(ctx).Table(r.getTable()).
+		Where("id = ?", id).
+		Update("title", title).Error
+
+	if err != nil {
+		ctx.Log.Error("failed to update title", zap.String("id", id), zap.Error(err))
+		return err
 	}
+	return nil
+}
+
+// DeleteByID deletes the link together with its click events. Only links
+// moved to the archive keep their clicks.
+func (r *urlRepository) DeleteByID(ctx *context.Context, id string) error {
+	err := ctx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table(clickEventTable).Where("url_id = ?", id).Delete(&models.ClickEvent{}).Error; err != nil {
+			return err
+		}
+		return tx.Table(r.getTable()).Where("id = ?", id).Delete(&models.URL{}).Error
+	})
 
-	err := query.Order("created_at DESC").Find(&urls).Error
 	if err != nil {
-		ctx.Log.Error("failed to list urls", zap.Error(err))
-		return nil, 0, err
+		ctx.Log.Error("failed to delete url", zap.String("id", id), zap.Error(err))
+		return err
 	}
+	return nil
+}
+
+// archive moves the rows matching the condition into the archive table,
+// keeping a JSON snapshot of each row.
+func (r *urlRepository) archive(ctx *context.Context, condition string, args ...interface{}) (int64, error) {
+	query := `WITH moved AS (
+		DELETE FROM ` + r.getTable() + ` WHERE ` + condition + ` RETURNING *
+	)
+	INSERT INTO ` + r.getArchiveTable() + ` (id, short_code, original_url, data, archived_at)
+	SELECT m.id, m.short_code, m.original_url, to_jsonb(m) - 'search_vector', NOW() FROM moved m`
+
+	result := ctx.DB.WithContext(ctx).Exec(query, args...)
+	return result.RowsAffected, result.Error
+}
 
-	return urls, total, nil
+func (r *urlRepository) ArchiveExpired(ctx *context.Context, cutoff time.Time) (int64, error) {
+	count, err := r.archive(ctx,
+		"(expires_at IS NOT NULL AND expires_at <= ?) OR (max_clicks IS NOT NULL AND click_count >= max_clicks AND last_accessed_at <= ?)",
+		cutoff, cutoff)
+	if err != nil {
+		ctx.Log.Error("failed to archive expired urls", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
 }
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mohan7-code/url-shortener/models"
+	"gorm.io/gorm"
+)
+
+// Columns links can be listed by. Each is paired with id so the order is
+// total even when values repeat.
+const (
+	SortCreatedAt    = "created_at"
+	SortClicks       = "click_count"
+	SortLastAccessed = "last_accessed_at"
+)
+
+// hostPattern captures the host of a destination URL. It is passed as a
+// parameter because gorm would take its "?" for a placeholder.
+const hostPattern = `^[A-Za-z][A-Za-z0-9+.-]*://([^/?#]+)`
+
+// headlineOptions makes ts_headline wrap every match of a title in the
+// models.HeadlineStart and models.HeadlineStop delimiters.
+const headlineOptions = "StartSel=" + models.HeadlineStart + ", StopSel=" + models.HeadlineStop + ", HighlightAll=true"
+
+// URLFilter narrows a listing. Zero fields do not filter.
+type URLFilter struct {
+	// Domain matches a substring of the destination's host.
+	Domain      string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	MinClicks   int64
+	Tag         string
+	Status      string
+}
+
+func (f URLFilter) apply(query *gorm.DB) *gorm.DB {
+	if f.Domain != "" {
+		query = query.Where(`substring(original_url from ?) ILIKE ? ESCAPE '\'`,
+			hostPattern, "%"+escapeLike(f.Domain)+"%")
+	}
+	if f.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *f.CreatedFrom)
+	}
+	if f.CreatedTo != nil {
+		query = query.Where("created_at < ?", *f.CreatedTo)
+	}
+	if f.MinClicks > 0 {
+		query = query.Where("click_count >= ?", f.MinClicks)
+	}
+	if f.Tag != "" {
+		tag, _ := json.Marshal([]string{f.Tag})
+		query = query.Where("tags @> ?::jsonb", string(tag))
+	}
+	if f.Status != "" {
+		query = query.Where("status = ?", f.Status)
+	}
+	return query
+}
+
+// KeysetPage selects one page of a listing ordered by Sort and id. When
+// AfterID is set the page starts right after the row with that sort value
+// and ID; Backward walks towards the start of the listing instead, from
+// right before that row.
+type KeysetPage struct {
+	Sort     string
+	Desc     bool
+	After    any
+	AfterID  uuid.UUID
+	Backward bool
+	Limit    int
+}
+
+func (p KeysetPage) apply(query *gorm.DB) *gorm.DB {
+	sort := p.Sort
+	if sort != SortClicks && sort != SortLastAccessed {
+		sort = SortCreatedAt
+	}
+
+	desc := p.Desc != p.Backward
+	direction, compare := " ASC", " > "
+	if desc {
+		direction, compare = " DESC", " < "
+	}
+
+	if p.AfterID != uuid.Nil {
+		query = query.Where("("+sort+", id)"+compare+"(?, ?)", p.After, p.AfterID)
+	}
+	return query.Order(sort + direction).Order("id" + direction).Limit(p.Limit)
+}
+
+// escapeLike makes s match literally inside a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
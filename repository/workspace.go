@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/mohan7-code/url-shortener/models"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type IWorkspaceRepository interface {
+	Create(ctx *context.Context, workspace *models.Workspace, admin *models.WorkspaceMember) error
+	ListForMember(ctx *context.Context, memberID string) ([]*models.WorkspaceWithRole, error)
+	GetMember(ctx *context.Context, workspaceID, memberID string) (*models.WorkspaceMember, error)
+	ListMembers(ctx *context.Context, workspaceID string) ([]*models.WorkspaceMember, error)
+	AddMember(ctx *context.Context, member *models.WorkspaceMember) error
+	UpdateMemberRole(ctx *context.Context, workspaceID, memberID, role string) error
+	RemoveMember(ctx *context.Context, workspaceID, memberID string) error
+	CountAdmins(ctx *context.Context, workspaceID string) (int64, error)
+}
+
+type workspaceRepository struct {
+}
+
+func NewWorkspaceRepository() IWorkspaceRepository {
+	return &workspaceRepository{}
+}
+
+func (r *workspaceRepository) getTable() string {
+	return "workspaces"
+}
+
+func (r *workspaceRepository) getMembersTable() string {
+	return "workspace_members"
+}
+
+// Create inserts the workspace together with its first admin.
+func (r *workspaceRepository) Create(ctx *context.Context, workspace *models.Workspace, admin *models.WorkspaceMember) error {
+	err := ctx.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table(r.getTable()).Create(workspace).Error; err != nil {
+			return err
+		}
+		return tx.Table(r.getMembersTable()).Create(admin).Error
+	})
+
+	if err != nil {
+		ctx.Log.Error("failed to create workspace", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *workspaceRepository) ListForMember(ctx *context.Context, memberID string) ([]*models.WorkspaceWithRole, error) {
+	var workspaces []*models.WorkspaceWithRole
+
+	err := ctx.DB.WithContext(ctx).Table(r.getTable()+" AS w").
+		Select("w.*, m.role").
+		Joins("JOIN "+r.getMembersTable()+" AS m ON m.workspace_id = w.id").
+		Where("m.member_id = ?", memberID).
+		Order("w.created_at").
+		Find(&workspaces).Error
+
+	if err != nil {
+		ctx.Log.Error("failed to list workspaces", zap.String("member_id", memberID), zap.Error(err))
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+func (r *workspaceRepository) GetMember(ctx *context.Context, workspaceID, memberID string) (*models.WorkspaceMember, error) {
+	var member models.WorkspaceMember
+
+	err := ctx.DB.WithContext(ctx).Table(r.getMembersTable()).
+		Where("workspace_id = ? AND member_id = ?", workspaceID, memberID).
+		First(&member).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		ctx.Log.Error("failed to get workspace member", zap.String("workspace_id", workspaceID), zap.Error(err))
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *workspaceRepository) ListMembers(ctx *context.Context, workspaceID string) ([]*models.WorkspaceMember, error) {
+	var members []*models.WorkspaceMember
+
+	err := ctx.DB.WithContext(ctx).Table(r.getMembersTable()).
+		Where("workspace_id = ?", workspaceID).
+		Order("created_at").
+		Find(&members).Error
+
+	if err != nil {
+		ctx.Log.Error("failed to list workspace members", zap.String("workspace_id", workspaceID), zap.Error(err))
+		return nil, err
+	}
+	return members, nil
+}
+
+func (r *workspaceRepository) AddMember(ctx *context.Context, member *models.WorkspaceMember) error {
+	err := ctx.DB.WithContext(ctx).Table(r.getMembersTable()).Create(member).Error
+	if err != nil {
+		ctx.Log.Error("failed to add workspace member", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *workspaceRepository) UpdateMemberRole(ctx *context.Context, workspaceID, memberID, role string) error {
+	err := ctx.DB.WithContext(ctx).Table(r.getMembersTable()).
+		Where("workspace_id = ? AND member_id = ?", workspaceID, memberID).
+		Update("role", role).Error
+
+	if err != nil {
+		ctx.Log.Error("failed to update workspace member", zap.String("workspace_id", workspaceID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *workspaceRepository) RemoveMember(ctx *context.Context, workspaceID, memberID string) error {
+	err := ctx.DB.WithContext(ctx).Table(r.getMembersTable()).
+		Where("workspace_id = ? AND member_id = ?", workspaceID, memberID).
+		Delete(&models.WorkspaceMember{}).Error
+
+	if err != nil {
+		ctx.Log.Error("failed to remove workspace member", zap.String("workspace_id", workspaceID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *workspaceRepository) CountAdmins(ctx *context.Context, workspaceID string) (int64, error) {
+	var total int64
+
+	err := ctx.DB.WithContext(ctx).Table(r.getMembersTable()).
+		Where("workspace_id = ? AND role = ?", workspaceID, models.RoleAdmin).
+		Count(&total).Error
+
+	if err != nil {
+		ctx.Log.Error("failed to count workspace admins", zap.String("workspace_id", workspaceID), zap.Error(err))
+		return 0, err
+	}
+	return total, nil
+}
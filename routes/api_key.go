@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	handler "github.com/mohan7-code/url-shortener/handlers"
+	mw "github.com/mohan7-code/url-shortener/middleware"
+)
+
+func KeyRoutes(router *gin.RouterGroup) {
+	router.POST("/keys", mw.MiddleWare(mw.RequireAuth(handler.CreateAPIKey)))
+	router.DELETE("/keys/:id", mw.MiddleWare(mw.RequireAuth(handler.RevokeAPIKey)))
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/mohan7-code/url-shortener/config"
+	handler "github.com/mohan7-code/url-shortener/handlers"
+	mw "github.com/mohan7-code/url-shortener/middleware"
+)
+
+func ModerationRoutes(router *gin.RouterGroup) {
+	router.POST("/report/:code", mw.RateLimited(config.RouteReport, handler.ReportLink))
+	router.GET("/admin/reports", mw.MiddleWare(mw.RequireAuth(mw.RequireAdmin(handler.ListReports))))
+	router.PATCH("/admin/urls/:code", mw.MiddleWare(mw.RequireAuth(mw.RequireAdmin(handler.ModerateURL))))
+	router.GET("/admin/urls/:code/audit", mw.MiddleWare(mw.RequireAuth(mw.RequireAdmin(handler.ListAuditTrail))))
+}
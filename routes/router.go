@@ -9,6 +9,9 @@ func GetRouter() *gin.Engine {
 	v1 := router.Group("/v1")
 
 	UrlRoutes(v1)
+	KeyRoutes(v1)
+	WorkspaceRoutes(v1)
+	ModerationRoutes(v1)
 
 	return router
 }
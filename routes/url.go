@@ -2,13 +2,26 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/mohan7-code/url-shortener/config"
 	handler "github.com/mohan7-code/url-shortener/handlers"
 	mw "github.com/mohan7-code/url-shortener/middleware"
 )
 
 func UrlRoutes(router *gin.RouterGroup) {
-	router.POST("/shorten", mw.MiddleWare(handler.CreateShortURL))
-	router.GET("/:shortCode", mw.MiddleWare(handler.RedirectURL))
-	router.GET("/urls", mw.MiddleWare(handler.ListURLs))
-	router.GET("/analytics/:code", mw.MiddleWare(handler.GetAnalytics))
+	router.POST("/shorten", mw.RateLimited(config.RouteShorten, mw.RequireAuth(mw.Idempotent(handler.CreateShortURL))))
+	router.POST("/shorten/batch", mw.RateLimited(config.RouteShorten, mw.RequireAuth(mw.Idempotent(handler.CreateShortURLBatch))))
+	router.GET("/:shortCode", mw.RateLimited(config.RouteRedirect, handler.RedirectURL))
+	router.HEAD("/:shortCode", mw.RateLimited(config.RouteRedirect, handler.RedirectURL))
+	router.POST("/:shortCode", mw.RateLimited(config.RouteRedirect, handler.UnlockURL))
+	router.GET("/urls", mw.MiddleWare(mw.RequireAuth(handler.ListURLs)))
+	router.GET("/urls/search", mw.MiddleWare(mw.RequireAuth(handler.SearchURLs)))
+	router.GET("/analytics/:code", mw.MiddleWare(mw.RequireAuth(handler.GetAnalytics)))
+	router.GET("/analytics/:code/clicks", mw.MiddleWare(mw.RequireAuth(handler.ListClickEvents)))
+	router.PATCH("/urls/:code", mw.MiddleWare(mw.RequireAuth(handler.UpdateURL)))
+	router.DELETE("/urls/:code", mw.MiddleWare(mw.RequireAuth(handler.DeleteURL)))
+	router.GET("/urls/:code/qr", mw.MiddleWare(mw.RequireAuth(handler.GetQRCode)))
+	router.POST("/urls/import", mw.RateLimited(config.RouteShorten, mw.RequireAuth(handler.ImportURLs)))
+	router.GET("/urls/import/:id", mw.MiddleWare(mw.RequireAuth(handler.GetImportJob)))
+	router.GET("/urls/import/:id/errors", mw.MiddleWare(mw.RequireAuth(handler.GetImportErrors)))
+	router.GET("/urls/export", mw.MiddleWare(mw.RequireAuth(handler.ExportURLs)))
 }
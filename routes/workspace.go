@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	handler "github.com/mohan7-code/url-shortener/handlers"
+	mw "github.com/mohan7-code/url-shortener/middleware"
+)
+
+func WorkspaceRoutes(router *gin.RouterGroup) {
+	router.POST("/workspaces", mw.MiddleWare(mw.RequireAuth(handler.CreateWorkspace)))
+	router.GET("/workspaces", mw.MiddleWare(mw.RequireAuth(handler.ListWorkspaces)))
+	router.GET("/workspaces/:id/members", mw.MiddleWare(mw.RequireAuth(handler.ListWorkspaceMembers)))
+	router.POST("/workspaces/:id/members", mw.MiddleWare(mw.RequireAuth(handler.AddWorkspaceMember)))
+	router.PATCH("/workspaces/:id/members/:memberId", mw.MiddleWare(mw.RequireAuth(handler.UpdateWorkspaceMember)))
+	router.DELETE("/workspaces/:id/members/:memberId", mw.MiddleWare(mw.RequireAuth(handler.RemoveWorkspaceMember)))
+	router.POST("/workspaces/:id/domains", mw.MiddleWare(mw.RequireAuth(handler.CreateWorkspaceDomain)))
+	router.GET("/workspaces/:id/domains", mw.MiddleWare(mw.RequireAuth(handler.ListWorkspaceDomains)))
+	router.DELETE("/workspaces/:id/domains/:domainId", mw.MiddleWare(mw.RequireAuth(handler.DeleteWorkspaceDomain)))
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"time"
+
+	"github.com/mohan7-code/url-shortener/dtos"
+)
+
+const (
+	IntervalHour  = "hour"
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+
+	defaultAnalyticsRange = 30 * 24 * time.Hour
+	maxAnalyticsBuckets   = 2000
+	topBreakdownItems     = 10
+)
+
+// truncateToInterval mirrors Postgres date_trunc in UTC, where weeks start on Monday.
+func truncateToInterval(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	switch interval {
+	case IntervalHour:
+		return t.Truncate(time.Hour)
+	case IntervalWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case IntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+func nextBucket(t time.Time, interval string) time.Time {
+	switch interval {
+	case IntervalHour:
+		return t.Add(time.Hour)
+	case IntervalWeek:
+		return t.AddDate(0, 0, 7)
+	case IntervalMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+func isValidInterval(interval string) bool {
+	switch interval {
+	case IntervalHour, IntervalDay, IntervalWeek, IntervalMonth:
+		return true
+	}
+	return false
+}
+
+// countBuckets returns how many buckets the range spans, stopping early once
+// the limit is passed.
+func countBuckets(from, to time.Time, interval string, limit int) int {
+	count := 0
+	for start := truncateToInterval(from, interval); start.Before(to); start = nextBucket(start, interval) {
+		count++
+		if count > limit {
+			break
+		}
+	}
+	return count
+}
+
+// fillTimeSeries returns one bucket per interval in [from, to), using the
+// counts from the database and zero everywhere else so charts have no gaps.
+func fillTimeSeries(counts []dtos.TimeBucket, from, to time.Time, interval string) []dtos.TimeBucket {
+	byStart := make(map[int64]int64, len(counts))
+	for _, bucket := range counts {
+		byStart[bucket.Start.UTC().Unix()] = bucket.Clicks
+	}
+
+	series := []dtos.TimeBucket{}
+	for start := truncateToInterval(from, interval); start.Before(to); start = nextBucket(start, interval) {
+		series = append(series, dtos.TimeBucket{
+			Start:  start,
+			Clicks: byStart[start.Unix()],
+		})
+	}
+	return series
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mohan7-code/url-shortener/config"
+	"github.com/mohan7-code/url-shortener/models"
+	"github.com/mohan7-code/url-shortener/repository"
+	"github.com/mohan7-code/url-shortener/utils/cache"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	"go.uber.org/zap"
+)
+
+const (
+	apiKeyPrefix   = "usk_"
+	apiKeyCacheTTL = 5 * time.Minute
+)
+
+type IAPIKeyService interface {
+	CreateKey(ctx *context.Context, ownerID uuid.UUID, name, plan string, admin bool) (*models.APIKey, string, error)
+	Authenticate(ctx *context.Context, rawKey string) (*models.APIKey, error)
+	RevokeKey(ctx *context.Context, id string) error
+}
+
+type apiKeyServiceImpl struct {
+	repo repository.IAPIKeyRepository
+}
+
+func NewAPIKeyService() IAPIKeyService {
+	return &apiKeyServiceImpl{
+		repo: repository.NewAPIKeyRepository(),
+	}
+}
+
+// CreateKey issues a new key for the owner on the given rate limit plan, or
+// the default plan when it is empty. Plans must be listed in
+// RATE_LIMIT_PLANS. Admin keys may moderate any link. The raw key is only
+// returned here; the database keeps its SHA-256 hash.
+func (s *apiKeyServiceImpl) CreateKey(ctx *context.Context, ownerID uuid.UUID, name, plan string, admin bool) (*models.APIKey, string, error) {
+
+	plan = strings.TrimSpace(plan)
+	if _, ok := config.AppConfig.RateLimitPlans[plan]; plan != "" && !ok {
+		return nil, "", ErrInvalidPlan
+	}
+
+	if ownerID == uuid.Nil {
+		ownerID = uuid.New()
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		ctx.Log.Error("failed to generate api key", zap.Error(err))
+		return nil, "", err
+	}
+	rawKey := apiKeyPrefix + hex.EncodeToString(secret)
+
+	key := &models.APIKey{
+		ID:        uuid.New(),
+		OwnerID:   ownerID,
+		Name:      strings.TrimSpace(name),
+		KeyPrefix: rawKey[:len(apiKeyPrefix)+8],
+		KeyHash:   hashAPIKey(rawKey),
+		Plan:      plan,
+		IsAdmin:   admin,
+	}
+
+	if err := s.repo.Create(ctx, key); err != nil {
+		ctx.Log.Error("failed to store api key", zap.Error(err))
+		return nil, "", err
+	}
+
+	ctx.Log.Info("api key created", zap.String("key_id", key.ID.String()), zap.String("owner_id", ownerID.String()))
+	return key, rawKey, nil
+}
+
+// Authenticate resolves a raw key into its active record, or returns
+// ErrInvalidAPIKey. Lookups are cached briefly in Redis.
+func (s *apiKeyServiceImpl) Authenticate(ctx *context.Context, rawKey string) (*models.APIKey, error) {
+
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	keyHash := hashAPIKey(rawKey)
+	rdb := cache.New().Client
+
+	if raw, err := rdb.Get(ctx, apiKeyCacheKey(keyHash)).Bytes(); err == nil {
+		var key models.APIKey
+		if err := json.Unmarshal(raw, &key); err == nil && key.ID != uuid.Nil {
+			return &key, nil
+		}
+	}
+
+	key, err := s.repo.GetActiveByHash(ctx, keyHash)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if raw, err := json.Marshal(key); err == nil {
+		rdb.Set(ctx, apiKeyCacheKey(keyHash), raw, apiKeyCacheTTL)
+	}
+
+	return key, nil
+}
+
+func (s *apiKeyServiceImpl) RevokeKey(ctx *context.Context, id string) error {
+
+	if _, err := uuid.Parse(id); err != nil {
+		return ErrAPIKeyNotFound
+	}
+
+	key, err := s.repo.Revoke(ctx, id, ctx.OwnerID.String())
+	if err != nil {
+		ctx.Log.Error("failed to revoke api key", zap.Error(err))
+		return err
+	}
+	if key == nil {
+		return ErrAPIKeyNotFound
+	}
+
+	if err := cache.New().Client.Del(ctx, apiKeyCacheKey(key.KeyHash)).Err(); err != nil {
+		ctx.Log.Warn("failed to drop cached api key", zap.String("key_id", id), zap.Error(err))
+	}
+
+	ctx.Log.Info("api key revoked", zap.String("key_id", id))
+	return nil
+}
+
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func apiKeyCacheKey(keyHash string) string {
+	return "apikey:" + keyHash
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/mohan7-code/url-shortener/models"
+	"github.com/mohan7-code/url-shortener/repository"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+)
+
+// action is what a caller wants to do with a link or workspace.
+type action int
+
+const (
+	actionRead   action = iota // list links, view analytics
+	actionWrite                // create, update and delete links
+	actionManage               // manage workspace members
+)
+
+func roleAllows(role string, act action) bool {
+	switch role {
+	case models.RoleAdmin:
+		return true
+	case models.RoleEditor:
+		return act <= actionWrite
+	case models.RoleViewer:
+		return act == actionRead
+	default:
+		return false
+	}
+}
+
+func isValidRole(role string) bool {
+	switch role {
+	case models.RoleAdmin, models.RoleEditor, models.RoleViewer:
+		return true
+	}
+	return false
+}
+
+// authorizeWorkspace checks that the caller may perform act in the
+// workspace. Non-members get ErrWorkspaceNotFound so workspace IDs are not
+// disclosed.
+func authorizeWorkspace(ctx *context.Context, repo repository.IWorkspaceRepository, workspaceID uuid.UUID, act action) error {
+	if !ctx.IsAuthenticated() {
+		return ErrUnauthorized
+	}
+
+	member, err := repo.GetMember(ctx, workspaceID.String(), ctx.OwnerID.String())
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return ErrWorkspaceNotFound
+	}
+	if !roleAllows(member.Role, act) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// authorizeLink checks that the caller may perform act on the link.
+// Personal links are only visible to their owner; workspace links follow the
+// caller's role in the workspace.
+func authorizeLink(ctx *context.Context, repo repository.IWorkspaceRepository, url *models.URL, act action) error {
+	if !ctx.IsAuthenticated() {
+		return ErrUnauthorized
+	}
+
+	if url.WorkspaceID == nil {
+		if url.OwnerID != nil && *url.OwnerID == ctx.OwnerID {
+			return nil
+		}
+		return ErrShortCodeNotFound
+	}
+
+	err := authorizeWorkspace(ctx, repo, *url.WorkspaceID, act)
+	if errors.Is(err, ErrWorkspaceNotFound) {
+		return ErrShortCodeNotFound
+	}
+	return err
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/mohan7-code/url-shortener/config"
+	"github.com/mohan7-code/url-shortener/dtos"
+	"github.com/mohan7-code/url-shortener/models"
+	"github.com/mohan7-code/url-shortener/repository"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	"go.uber.org/zap"
+)
+
+// ShortenResult is the outcome of one item of a batch. Created is false when
+// the item was answered with an existing link.
+type ShortenResult struct {
+	URL     *models.URL
+	Created bool
+	Err     error
+}
+
+// ShortenBatch handles up to ShortenBatchLimit shorten requests at once.
+// Every item is validated like ShortenURL and fails on its own; the new links
+// are then inserted in one transaction in which a row the database rejects,
+// such as an alias claimed concurrently, only fails the items that need it.
+// Plain items for the same destination share one new link.
+func (s *urlServiceImpl) ShortenBatch(ctx *context.Context, reqs []dtos.URLRequest) ([]ShortenResult, error) {
+
+	if len(reqs) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	if len(reqs) > config.AppConfig.ShortenBatchLimit {
+		return nil, ErrBatchTooLarge
+	}
+
+	results := make([]ShortenResult, len(reqs))
+	reserved := make(map[string]bool)
+	sharedByDest := make(map[string]*pendingLink)
+	var created []*pendingLink
+	// waiting maps the items whose link is only saved by CreateMany to the
+	// index of that link in created
+	waiting := make(map[int]int)
+	createdIndex := make(map[*pendingLink]int)
+
+	for i := range reqs {
+		link, err := s.prepareLink(ctx, &reqs[i], reserved)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		if link.reused {
+			results[i].URL = link.url
+			continue
+		}
+
+		if link.shared {
+			earlier, ok := sharedByDest[link.destKey]
+			if ok && reusesExisting(&reqs[i]) {
+				delete(reserved, codeCacheKey(link.url.DomainName(), link.url.ShortCode))
+				results[i].URL = earlier.url
+				waiting[i] = createdIndex[earlier]
+				continue
+			}
+			if !ok {
+				sharedByDest[link.destKey] = link
+			}
+		}
+
+		results[i] = ShortenResult{URL: link.url, Created: true}
+		createdIndex[link] = len(created)
+		waiting[i] = len(created)
+		created = append(created, link)
+	}
+
+	if len(created) == 0 {
+		return results, nil
+	}
+
+	urls := make([]*models.URL, len(created))
+	for i, link := range created {
+		urls[i] = link.url
+	}
+	errs, err := s.repo.CreateMany(ctx, urls)
+	if err != nil {
+		for i := range waiting {
+			results[i] = ShortenResult{Err: err}
+		}
+		return results, nil
+	}
+
+	for i, j := range waiting {
+		if errs[j] != nil {
+			results[i] = ShortenResult{Err: createError(&reqs[i], errs[j])}
+		}
+	}
+
+	saved := make([]*pendingLink, 0, len(created))
+	for j, link := range created {
+		if errs[j] == nil {
+			saved = append(saved, link)
+		}
+	}
+	if err := setCachedLinks(ctx, saved); err != nil {
+		ctx.Log.Warn("failed to cache batch links", zap.Error(err))
+	}
+
+	ctx.Log.Info("shortened URL batch created", zap.Int("items", len(reqs)), zap.Int("created", len(saved)))
+	return results, nil
+}
+
+// createError maps the error of saving a request's link. A custom alias
+// that was taken after it was checked is reported as ErrAliasTaken.
+func createError(req *dtos.URLRequest, err error) error {
+	if req.CustomAlias != "" && errors.Is(err, repository.ErrDuplicateCode) {
+		return ErrAliasTaken
+	}
+	return err
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	helper "github.com/mohan7-code/url-shortener/utils/helpers"
+	"go.uber.org/zap"
+)
+
+var activeBlocklist atomic.Pointer[blocklistEntries]
+
+// hostsFileNames are the loopback aliases every hosts file starts with;
+// they are not blocklist entries.
+var hostsFileNames = map[string]bool{
+	"localhost":             true,
+	"localhost.localdomain": true,
+	"local":                 true,
+	"broadcasthost":         true,
+	"ip6-localhost":         true,
+	"ip6-loopback":          true,
+}
+
+// Blocklist loads blocked domains and URLs from a file and reloads it
+// whenever the file changes. Each line is one of:
+//
+//	0.0.0.0 phishing.example     hosts-file style, any address
+//	phishing.example             a bare domain, subdomains included
+//	https://example.com/phish    a URL, blocking every URL it prefixes
+//
+// Blank lines and text after "#" are ignored.
+type Blocklist struct {
+	path     string
+	interval time.Duration
+	modTime  time.Time
+	size     int64
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+type blocklistEntries struct {
+	domains map[string]bool
+	urls    []string
+}
+
+func NewBlocklist(path string, interval time.Duration) *Blocklist {
+	return &Blocklist{
+		path:     path,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start loads the file and keeps polling it for changes.
+func (b *Blocklist) Start(ctx *context.Context) {
+	b.reload(ctx)
+
+	go func() {
+		defer close(b.done)
+
+		ticker := time.NewTicker(b.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				b.reload(ctx)
+			case <-b.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (b *Blocklist) Stop() {
+	close(b.stop)
+	<-b.done
+}
+
+// reload parses the file again if its size or modification time changed.
+// A file that cannot be read keeps the previous entries in place.
+func (b *Blocklist) reload(ctx *context.Context) {
+	info, err := os.Stat(b.path)
+	if err != nil {
+		ctx.Log.Warn("failed to stat blocklist", zap.String("path", b.path), zap.Error(err))
+		return
+	}
+	if info.ModTime().Equal(b.modTime) && info.Size() == b.size {
+		return
+	}
+
+	file, err := os.Open(b.path)
+	if err != nil {
+		ctx.Log.Warn("failed to open blocklist", zap.String("path", b.path), zap.Error(err))
+		return
+	}
+	defer file.Close()
+
+	entries, err := parseBlocklist(file)
+	if err != nil {
+		ctx.Log.Warn("failed to read blocklist", zap.String("path", b.path), zap.Error(err))
+		return
+	}
+
+	activeBlocklist.Store(entries)
+	b.modTime, b.size = info.ModTime(), info.Size()
+
+	ctx.Log.Info("blocklist loaded", zap.String("path", b.path),
+		zap.Int("domains", len(entries.domains)), zap.Int("urls", len(entries.urls)))
+}
+
+func parseBlocklist(r io.Reader) (*blocklistEntries, error) {
+	entries := &blocklistEntries{domains: make(map[string]bool)}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if len(fields) > 1 && net.ParseIP(fields[0]) != nil {
+			for _, name := range fields[1:] {
+				if name = normalizeBlockedDomain(name); name != "" && !hostsFileNames[name] && net.ParseIP(name) == nil {
+					entries.domains[name] = true
+				}
+			}
+			continue
+		}
+
+		entry := fields[0]
+		if strings.Contains(entry, "://") {
+			normalized, err := helper.NormalizeURL(entry, helper.NormalizeOptions{
+				LowercaseHost:     true,
+				RemoveDefaultPort: true,
+				Punycode:          true,
+				CleanPath:         true,
+			})
+			if err == nil {
+				entries.urls = append(entries.urls, normalized)
+			}
+			continue
+		}
+
+		if name := normalizeBlockedDomain(entry); name != "" {
+			entries.domains[name] = true
+		}
+	}
+	return entries, scanner.Err()
+}
+
+func normalizeBlockedDomain(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.TrimPrefix(name, "*.")
+	return strings.Trim(name, ".")
+}
+
+// blocked reports the entry that blocks the URL, if any.
+func (e *blocklistEntries) blocked(host, normalizedURL string) (string, bool) {
+	if domain, ok := matchDomain(host, e.domains); ok {
+		return domain, true
+	}
+	for _, prefix := range e.urls {
+		if strings.HasPrefix(normalizedURL, prefix) {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// matchDomain reports whether host or one of its parent domains is in the set.
+func matchDomain(host string, domains map[string]bool) (string, bool) {
+	for name := host; name != ""; {
+		if domains[name] {
+			return name, true
+		}
+		_, parent, ok := strings.Cut(name, ".")
+		if !ok {
+			break
+		}
+		name = parent
+	}
+	return "", false
+}
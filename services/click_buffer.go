@@ -0,0 +1,121 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mohan7-code/url-shortener/models"
+	"github.com/mohan7-code/url-shortener/repository"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	"go.uber.org/zap"
+)
+
+var (
+	bufferMu    sync.RWMutex
+	clickBuffer *ClickBuffer
+)
+
+// ClickBuffer queues click events in memory and writes them in batches, so
+// redirects never wait on a Postgres write. Pending events are flushed when
+// the batch fills up, on every tick, and once more when the buffer stops.
+type ClickBuffer struct {
+	clicks    repository.IClickEventRepository
+	events    chan *models.ClickEvent
+	batchSize int
+	interval  time.Duration
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+func NewClickBuffer(capacity, batchSize int, interval time.Duration) *ClickBuffer {
+	return &ClickBuffer{
+		clicks:    repository.NewClickEventRepository(),
+		events:    make(chan *models.ClickEvent, capacity),
+		batchSize: batchSize,
+		interval:  interval,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Start runs the flusher and makes the buffer the one used by redirects.
+func (b *ClickBuffer) Start(ctx *context.Context) {
+	go b.run(ctx)
+
+	bufferMu.Lock()
+	clickBuffer = b
+	bufferMu.Unlock()
+}
+
+// Stop detaches the buffer from redirects, flushes everything still queued
+// and waits for the flusher to exit.
+func (b *ClickBuffer) Stop() {
+	bufferMu.Lock()
+	if clickBuffer == b {
+		clickBuffer = nil
+	}
+	bufferMu.Unlock()
+
+	close(b.stop)
+	<-b.done
+}
+
+// Enqueue adds an event without blocking. It returns false when the buffer
+// is full so the caller can fall back to a synchronous write.
+func (b *ClickBuffer) Enqueue(event *models.ClickEvent) bool {
+	select {
+	case b.events <- event:
+		return true
+	default:
+		return false
+	}
+}
+
+func (b *ClickBuffer) run(ctx *context.Context) {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	batch := make([]*models.ClickEvent, 0, b.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := b.clicks.RecordClicks(ctx, batch); err != nil {
+			ctx.Log.Error("failed to flush clicks", zap.Int("dropped", len(batch)), zap.Error(err))
+		}
+		batch = make([]*models.ClickEvent, 0, b.batchSize)
+	}
+
+	for {
+		select {
+		case event := <-b.events:
+			batch = append(batch, event)
+			if len(batch) >= b.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-b.stop:
+			for {
+				select {
+				case event := <-b.events:
+					batch = append(batch, event)
+					if len(batch) >= b.batchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func getClickBuffer() *ClickBuffer {
+	bufferMu.RLock()
+	defer bufferMu.RUnlock()
+	return clickBuffer
+}
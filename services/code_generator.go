@@ -0,0 +1,163 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+	"math/bits"
+
+	"github.com/mohan7-code/url-shortener/config"
+	"github.com/mohan7-code/url-shortener/repository"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+)
+
+const (
+	base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+	// maxCodeAttempts bounds how often ShortenURL asks for a new code when
+	// the previous one was already taken by a custom alias or a collision.
+	maxCodeAttempts = 5
+)
+
+// CodeGenerator produces candidate short codes for new links.
+type CodeGenerator interface {
+	Generate(ctx *context.Context) (string, error)
+}
+
+// NewCodeGenerator returns the generator selected by CODE_STRATEGY.
+func NewCodeGenerator(repo repository.IURLRepository) CodeGenerator {
+	cfg := config.AppConfig
+	switch cfg.CodeStrategy {
+	case config.CodeStrategySequence:
+		return &sequenceGenerator{repo: repo, length: cfg.CodeLength}
+	case config.CodeStrategyHashids:
+		return newHashidsGenerator(repo, cfg.CodeLength, cfg.CodeSalt)
+	default:
+		return &randomGenerator{length: cfg.CodeLength}
+	}
+}
+
+// sequenceGenerator encodes the next value of a Postgres sequence in base62,
+// left-padded to length. Codes never collide with each other but are easy
+// to enumerate.
+type sequenceGenerator struct {
+	repo   repository.IURLRepository
+	length int
+}
+
+func (g *sequenceGenerator) Generate(ctx *context.Context) (string, error) {
+	n, err := g.repo.NextCodeSequence(ctx)
+	if err != nil {
+		return "", err
+	}
+	return encodeBase62(uint64(n), g.length, base62Alphabet), nil
+}
+
+// randomGenerator draws length characters uniformly from the base62
+// alphabet, giving 62^length possible codes.
+type randomGenerator struct {
+	length int
+}
+
+func (g *randomGenerator) Generate(ctx *context.Context) (string, error) {
+	code := make([]byte, g.length)
+	limit := big.NewInt(int64(len(base62Alphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, limit)
+		if err != nil {
+			return "", err
+		}
+		code[i] = base62Alphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// hashidsGenerator obfuscates sequence values in the spirit of Hashids: the
+// value is run through a salted bijection of the 62^n codes of its length
+// and written with a salt-shuffled alphabet. Codes stay unique like the
+// sequence strategy but consecutive links no longer look related.
+type hashidsGenerator struct {
+	repo       repository.IURLRepository
+	length     int
+	alphabet   string
+	multiplier uint64
+	offset     uint64
+}
+
+func newHashidsGenerator(repo repository.IURLRepository, length int, salt string) *hashidsGenerator {
+	sum := sha256.Sum256([]byte(salt))
+
+	// the multiplier must be coprime with 62^n, i.e. odd and not a multiple of 31
+	multiplier := binary.BigEndian.Uint64(sum[0:8]) | 1
+	for multiplier%31 == 0 {
+		multiplier += 2
+	}
+
+	return &hashidsGenerator{
+		repo:       repo,
+		length:     length,
+		alphabet:   shuffleAlphabet(base62Alphabet, salt),
+		multiplier: multiplier,
+		offset:     binary.BigEndian.Uint64(sum[8:16]),
+	}
+}
+
+func (g *hashidsGenerator) Generate(ctx *context.Context) (string, error) {
+	n, err := g.repo.NextCodeSequence(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	value := uint64(n)
+	length := max(g.length, base62Len(value))
+
+	space := uint64(1)
+	for range length {
+		space *= uint64(len(base62Alphabet))
+	}
+
+	// value*multiplier + offset (mod space) is a bijection on [0, space)
+	hi, lo := bits.Mul64(value, g.multiplier)
+	_, mixed := bits.Div64(hi%space, lo, space)
+	mixed = (mixed + g.offset%space) % space
+
+	return encodeBase62(mixed, length, g.alphabet), nil
+}
+
+// encodeBase62 writes n with the given alphabet, left-padded to length.
+func encodeBase62(n uint64, length int, alphabet string) string {
+	base := uint64(len(alphabet))
+
+	code := make([]byte, max(length, base62Len(n)))
+	for i := len(code) - 1; i >= 0; i-- {
+		code[i] = alphabet[n%base]
+		n /= base
+	}
+	return string(code)
+}
+
+func base62Len(n uint64) int {
+	length := 1
+	for n >= uint64(len(base62Alphabet)) {
+		n /= uint64(len(base62Alphabet))
+		length++
+	}
+	return length
+}
+
+// shuffleAlphabet is the salted "consistent shuffle" used by Hashids.
+func shuffleAlphabet(alphabet, salt string) string {
+	result := []byte(alphabet)
+	if salt == "" {
+		return alphabet
+	}
+
+	for i, v, p := len(result)-1, 0, 0; i > 0; i, v = i-1, v+1 {
+		v %= len(salt)
+		p += int(salt[v])
+		j := (int(salt[v]) + v + p) % i
+		result[i], result[j] = result[j], result[i]
+	}
+	return string(result)
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"net"
+	neturl "net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mohan7-code/url-shortener/config"
+	"github.com/mohan7-code/url-shortener/models"
+	"github.com/mohan7-code/url-shortener/repository"
+	"github.com/mohan7-code/url-shortener/utils/cache"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	"go.uber.org/zap"
+)
+
+const domainCacheTTL = 5 * time.Minute
+
+var hostnamePattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+
+type IDomainService interface {
+	RegisterDomain(ctx *context.Context, workspaceID uuid.UUID, hostname string) (*models.Domain, error)
+	ListDomains(ctx *context.Context, workspaceID uuid.UUID) ([]*models.Domain, error)
+	RemoveDomain(ctx *context.Context, workspaceID, domainID uuid.UUID) error
+}
+
+type domainServiceImpl struct {
+	repo       repository.IDomainRepository
+	workspaces repository.IWorkspaceRepository
+}
+
+func NewDomainService() IDomainService {
+	return &domainServiceImpl{
+		repo:       repository.NewDomainRepository(),
+		workspaces: repository.NewWorkspaceRepository(),
+	}
+}
+
+func (s *domainServiceImpl) RegisterDomain(ctx *context.Context, workspaceID uuid.UUID, hostname string) (*models.Domain, error) {
+
+	if err := authorizeWorkspace(ctx, s.workspaces, workspaceID, actionManage); err != nil {
+		return nil, err
+	}
+
+	hostname = normalizeHost(hostname)
+	if !hostnamePattern.MatchString(hostname) || hostname == defaultShortHost() {
+		return nil, ErrInvalidDomain
+	}
+
+	existing, err := s.repo.GetByHostname(ctx, hostname)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrDomainTaken
+	}
+
+	domain := &models.Domain{
+		ID:          uuid.New(),
+		WorkspaceID: workspaceID,
+		Hostname:    hostname,
+	}
+	if err := s.repo.Create(ctx, domain); err != nil {
+		ctx.Log.Error("failed to register domain", zap.Error(err))
+		return nil, err
+	}
+
+	cache.New().Client.Del(ctx, domainCacheKey(hostname))
+
+	ctx.Log.Info("domain registered", zap.String("hostname", hostname), zap.String("workspace_id", workspaceID.String()))
+	return domain, nil
+}
+
+func (s *domainServiceImpl) ListDomains(ctx *context.Context, workspaceID uuid.UUID) ([]*models.Domain, error) {
+
+	if err := authorizeWorkspace(ctx, s.workspaces, workspaceID, actionRead); err != nil {
+		return nil, err
+	}
+
+	domains, err := s.repo.ListByWorkspace(ctx, workspaceID.String())
+	if err != nil {
+		ctx.Log.Error("failed to list domains", zap.Error(err))
+		return nil, err
+	}
+	return domains, nil
+}
+
+// RemoveDomain unregisters a domain that no longer has links on it.
+func (s *domainServiceImpl) RemoveDomain(ctx *context.Context, workspaceID, domainID uuid.UUID) error {
+
+	if err := authorizeWorkspace(ctx, s.workspaces, workspaceID, actionManage); err != nil {
+		return err
+	}
+
+	domain, err := s.repo.GetByID(ctx, domainID.String())
+	if err != nil {
+		return err
+	}
+	if domain == nil || domain.WorkspaceID != workspaceID {
+		return ErrDomainNotFound
+	}
+
+	links, err := s.repo.CountLinks(ctx, domain.Hostname)
+	if err != nil {
+		return err
+	}
+	if links > 0 {
+		return ErrDomainInUse
+	}
+
+	if err := s.repo.Delete(ctx, domain.ID.String()); err != nil {
+		ctx.Log.Error("failed to remove domain", zap.Error(err))
+		return err
+	}
+
+	cache.New().Client.Del(ctx, domainCacheKey(domain.Hostname))
+
+	ctx.Log.Info("domain removed", zap.String("hostname", domain.Hostname))
+	return nil
+}
+
+// resolveDomain maps a request Host to a registered branded domain. Unknown
+// hosts, including the default short host, resolve to "" (the default domain).
+func resolveDomain(ctx *context.Context, repo repository.IDomainRepository, host string) string {
+	host = normalizeHost(host)
+	if host == "" || host == defaultShortHost() {
+		return ""
+	}
+
+	rdb := cache.New().Client
+	if registered, err := rdb.Get(ctx, domainCacheKey(host)).Result(); err == nil {
+		if registered == "1" {
+			return host
+		}
+		return ""
+	}
+
+	domain, err := repo.GetByHostname(ctx, host)
+	if err != nil {
+		// fall back to the default domain rather than failing the redirect
+		ctx.Log.Warn("failed to resolve domain", zap.String("host", host), zap.Error(err))
+		return ""
+	}
+
+	registered := "0"
+	if domain != nil {
+		registered = "1"
+	}
+	rdb.Set(ctx, domainCacheKey(host), registered, domainCacheTTL)
+
+	if domain == nil {
+		return ""
+	}
+	return host
+}
+
+// ShortURL builds the public short URL of a link. Links on a branded domain
+// keep the scheme and path of BASE_SHORT_URL with the host swapped out.
+func ShortURL(url *models.URL) string {
+	base := strings.TrimRight(config.AppConfig.BaseShortURL, "/")
+	if domain := url.DomainName(); domain != "" {
+		if parsed, err := neturl.Parse(base); err == nil && parsed.Host != "" {
+			parsed.Host = domain
+			base = parsed.String()
+		}
+	}
+	return base + "/" + url.ShortCode
+}
+
+func defaultShortHost() string {
+	parsed, err := neturl.Parse(config.AppConfig.BaseShortURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
+
+// normalizeHost lowercases a host and strips any port and trailing dot.
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(host, ".")
+}
+
+func domainCacheKey(host string) string {
+	return "domain:" + host
+}
@@ -0,0 +1,65 @@
+package service
+
+import "errors"
+
+var (
+	ErrShortCodeNotFound     = errors.New("short code not found")
+	ErrInvalidURL            = errors.New("invalid URL format — must be a valid  URL")
+	ErrEmptyURL              = errors.New("original URL cannot be empty")
+	ErrAliasTaken            = errors.New("custom alias already taken, please choose another one")
+	ErrInvalidAlias          = errors.New("custom alias must be 1 to 10 letters, digits, - or _")
+	ErrInvalidExpiry         = errors.New("expires_at must be in the future")
+	ErrInvalidMaxClicks      = errors.New("max_clicks must be greater than zero")
+	ErrLinkGone              = errors.New("link has expired or reached its click limit")
+	ErrInvalidTimeRange      = errors.New("invalid time range, from must be before to")
+	ErrInvalidInterval       = errors.New("invalid interval, must be one of hour, day, week or month")
+	ErrTooManyBuckets        = errors.New("time range is too large for the requested interval")
+	ErrInvalidAPIKey         = errors.New("invalid or revoked API key")
+	ErrAPIKeyNotFound        = errors.New("api key not found")
+	ErrUnauthorized          = errors.New("authentication required")
+	ErrForbidden             = errors.New("your role does not allow this action")
+	ErrWorkspaceNotFound     = errors.New("workspace not found")
+	ErrMemberNotFound        = errors.New("workspace member not found")
+	ErrMemberExists          = errors.New("already a member of this workspace")
+	ErrInvalidRole           = errors.New("invalid role, must be one of admin, editor or viewer")
+	ErrLastAdmin             = errors.New("a workspace must keep at least one admin")
+	ErrInvalidWorkspace      = errors.New("workspace name cannot be empty")
+	ErrInvalidDomain         = errors.New("invalid domain, expected a hostname such as go.example.com")
+	ErrDomainTaken           = errors.New("domain is already registered")
+	ErrDomainNotFound        = errors.New("domain not found")
+	ErrDomainInUse           = errors.New("domain still has links, delete them first")
+	ErrDomainNotAllowed      = errors.New("domain is not registered for this workspace")
+	ErrUnsafeURL             = errors.New("destination URL was rejected by screening")
+	ErrCodeSpaceExhausted    = errors.New("could not generate a free short code, try again or use a custom alias")
+	ErrInvalidQRFormat       = errors.New("invalid format, must be png or svg")
+	ErrInvalidQRSize         = errors.New("invalid size, must be between 64 and 2048 pixels")
+	ErrInvalidQRMargin       = errors.New("invalid margin, must be between 0 and 32 modules")
+	ErrInvalidQRLevel        = errors.New("invalid level, must be one of L, M, Q or H")
+	ErrInvalidQRColor        = errors.New("invalid color, expected a hex value such as 1a2b3c")
+	ErrAdminOnly             = errors.New("this action requires an admin key")
+	ErrLinkDisabled          = errors.New("link has been disabled")
+	ErrLinkModerated         = errors.New("link is disabled or under review and cannot be changed")
+	ErrInvalidReason         = errors.New("invalid reason, must be one of phishing, malware, spam or other")
+	ErrInvalidStatus         = errors.New("invalid status, must be one of active, disabled or under_review")
+	ErrStatusUnchanged       = errors.New("link already has this status")
+	ErrInvalidPassword       = errors.New("password must be between 4 and 72 bytes")
+	ErrPasswordRequired      = errors.New("this link is password protected")
+	ErrWrongPassword         = errors.New("incorrect password")
+	ErrTooManyAttempts       = errors.New("too many incorrect passwords, try again later")
+	ErrInvalidTargeting      = errors.New("invalid targeting rule, each rule needs a destination and at least one os, device, language or country condition with known values")
+	ErrTooManyTargetingRules = errors.New("too many targeting rules, at most 20 are allowed")
+	ErrInvalidVariants       = errors.New("invalid variants, give 2 to 10 variants with unique names of letters, digits, - or _ and weights between 1 and 1000")
+	ErrInvalidRedirectType   = errors.New("invalid redirect type, use 301, 302, 307 or 308")
+	ErrEmptyBatch            = errors.New("batch must contain at least one item")
+	ErrBatchTooLarge         = errors.New("batch has too many items")
+	ErrInvalidTags           = errors.New("invalid tags, give at most 10 tags of up to 32 lowercase letters, digits, - or _")
+	ErrInvalidImportFormat   = errors.New("invalid format, use csv or ndjson")
+	ErrImportJobNotFound     = errors.New("import job not found")
+	ErrImportTooLarge        = errors.New("import file is too large")
+	ErrInvalidSort           = errors.New("invalid sort, use created_at, clicks or last_accessed with order asc or desc")
+	ErrInvalidCursor         = errors.New("invalid cursor, it must come from a listing with the same sort and order")
+	ErrInvalidTitle          = errors.New("title must be at most 200 characters, without control characters")
+	ErrInvalidSearch         = errors.New("search query must be between 1 and 200 characters")
+	ErrInvalidPlan           = errors.New("unknown plan, it must be one of RATE_LIMIT_PLANS")
+	ErrNothingToUpdate       = errors.New("nothing to update, set at least one of original_url, targeting, variants, redirect_type, tags or title")
+)
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"time"
+
+	"github.com/mohan7-code/url-shortener/repository"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	"go.uber.org/zap"
+)
+
+// ExpirySweeper periodically moves expired and exhausted links into the
+// archive table. Dead links are kept in place for the retention period so
+// redirects keep answering 410 Gone before the code disappears.
+type ExpirySweeper struct {
+	repo      repository.IURLRepository
+	interval  time.Duration
+	retention time.Duration
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+func NewExpirySweeper(interval, retention time.Duration) *ExpirySweeper {
+	return &ExpirySweeper{
+		repo:      repository.NewURLRepository(),
+		interval:  interval,
+		retention: retention,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+func (w *ExpirySweeper) Start(ctx *context.Context) {
+	go func() {
+		defer close(w.done)
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				w.sweep(ctx)
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the sweeper and waits for an in-flight sweep to finish.
+func (w *ExpirySweeper) Stop() {
+	close(w.stop)
+	<-w.done
+}
+
+func (w *ExpirySweeper) sweep(ctx *context.Context) {
+	count, err := w.repo.ArchiveExpired(ctx, time.Now().Add(-w.retention))
+	if err != nil {
+		ctx.Log.Error("expiry sweep failed", zap.Error(err))
+		return
+	}
+	if count > 0 {
+		ctx.Log.Info("archived expired links", zap.Int64("count", count))
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"net/netip"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	"github.com/mohan7-code/url-shortener/utils/geoip"
+	"go.uber.org/zap"
+)
+
+var activeGeoIP atomic.Pointer[geoip.Reader]
+
+// GeoIP loads a MaxMind-format country database from a local file and
+// reloads it when the process receives SIGHUP, so the file can be replaced
+// with a newer release without a restart.
+type GeoIP struct {
+	path string
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewGeoIP(path string) *GeoIP {
+	return &GeoIP{
+		path: path,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+}
+
+// Start loads the database and reloads it on every SIGHUP.
+func (g *GeoIP) Start(ctx *context.Context) {
+	g.reload(ctx)
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	go func() {
+		defer close(g.done)
+		defer signal.Stop(hup)
+
+		for {
+			select {
+			case <-hup:
+				g.reload(ctx)
+			case <-g.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (g *GeoIP) Stop() {
+	close(g.stop)
+	<-g.done
+}
+
+// reload reads the file again. A file that cannot be read keeps the
+// previous database in place.
+func (g *GeoIP) reload(ctx *context.Context) {
+	reader, err := geoip.Open(g.path)
+	if err != nil {
+		ctx.Log.Warn("failed to load geoip database", zap.String("path", g.path), zap.Error(err))
+		return
+	}
+
+	activeGeoIP.Store(reader)
+	ctx.Log.Info("geoip database loaded", zap.String("path", g.path), zap.String("type", reader.DatabaseType))
+}
+
+// LookupCountry returns the ISO country code of a client IP, or "" when no
+// database is loaded or it has no country for the address.
+func LookupCountry(ip string) string {
+	reader := activeGeoIP.Load()
+	if reader == nil {
+		return ""
+	}
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+
+	country, err := reader.Country(addr)
+	if err != nil {
+		return ""
+	}
+	return country
+}
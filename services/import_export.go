@@ -0,0 +1,265 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/mohan7-code/url-shortener/config"
+	"github.com/mohan7-code/url-shortener/dtos"
+	"github.com/mohan7-code/url-shortener/models"
+	"github.com/mohan7-code/url-shortener/repository"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	"go.uber.org/zap"
+)
+
+// importChunkSize is how many rows are created per ShortenBatch call.
+const importChunkSize = 100
+
+var (
+	runningImports sync.WaitGroup
+	stopImports    = make(chan struct{})
+)
+
+type IImportExportService interface {
+	StartImport(ctx *context.Context, src io.Reader, format string, workspaceID *uuid.UUID) (*models.ImportJob, error)
+	GetImportJob(ctx *context.Context, id string) (*models.ImportJob, error)
+	ExportURLs(ctx *context.Context, workspaceID uuid.UUID, fn func(*models.URL) error) error
+}
+
+type importExportServiceImpl struct {
+	jobs       repository.IImportJobRepository
+	urls       repository.IURLRepository
+	workspaces repository.IWorkspaceRepository
+	links      IURLService
+}
+
+func NewImportExportService() IImportExportService {
+	return &importExportServiceImpl{
+		jobs:       repository.NewImportJobRepository(),
+		urls:       repository.NewURLRepository(),
+		workspaces: repository.NewWorkspaceRepository(),
+		links:      NewURLService(),
+	}
+}
+
+// StartImport saves the upload to a temporary file and creates its links in
+// the background, returning the pending job straight away. Rows go through
+// ShortenBatch in chunks, so each is validated like a /shorten request and
+// fails on its own, even when the database rejects it.
+func (s *importExportServiceImpl) StartImport(ctx *context.Context, src io.Reader, format string, workspaceID *uuid.UUID) (*models.ImportJob, error) {
+
+	if !ctx.IsAuthenticated() {
+		return nil, ErrUnauthorized
+	}
+	if format != models.FormatCSV && format != models.FormatNDJSON {
+		return nil, ErrInvalidImportFormat
+	}
+	if workspaceID != nil {
+		if err := authorizeWorkspace(ctx, s.workspaces, *workspaceID, actionWrite); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := os.CreateTemp("", "import-*."+format)
+	if err != nil {
+		ctx.Log.Error("failed to create import file", zap.Error(err))
+		return nil, err
+	}
+	if _, err := io.Copy(file, src); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, ErrImportTooLarge
+		}
+		ctx.Log.Error("failed to save import file", zap.Error(err))
+		return nil, err
+	}
+
+	job := &models.ImportJob{
+		ID:          uuid.New(),
+		OwnerID:     ctx.OwnerID,
+		WorkspaceID: workspaceID,
+		Format:      format,
+		Status:      models.ImportPending,
+	}
+	if err := s.jobs.Create(ctx, job); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+
+	// the request context is recycled once the handler returns
+	bgCtx := ctx.Copy()
+	runningImports.Add(1)
+	go func() {
+		defer runningImports.Done()
+		defer os.Remove(file.Name())
+		defer file.Close()
+
+		s.runImport(bgCtx, job, file)
+	}()
+
+	ctx.Log.Info("import job started", zap.String("job_id", job.ID.String()), zap.String("format", format))
+	return job, nil
+}
+
+// StopImports interrupts running imports after their current chunk and waits
+// for them to record how far they got. It is called once on shutdown.
+func StopImports() {
+	close(stopImports)
+	runningImports.Wait()
+}
+
+func (s *importExportServiceImpl) runImport(ctx *context.Context, job *models.ImportJob, file *os.File) {
+	job.Status = models.ImportRunning
+	s.jobs.UpdateProgress(ctx, job)
+
+	var report bytes.Buffer
+	reportWriter := csv.NewWriter(&report)
+	reportWriter.Write([]string{"line", "destination", "error"})
+	fail := func(row importRow, err error) {
+		job.FailedRows++
+		reportWriter.Write([]string{strconv.Itoa(row.line), row.row.Destination, err.Error()})
+	}
+
+	finish := func(status, message string) {
+		reportWriter.Flush()
+		job.Status = status
+		job.Error = message
+		if job.FailedRows > 0 {
+			job.ErrorReport = report.String()
+		}
+		s.jobs.Finish(ctx, job)
+		ctx.Log.Info("import job finished",
+			zap.String("job_id", job.ID.String()),
+			zap.String("status", status),
+			zap.Int("succeeded", job.SucceededRows),
+			zap.Int("failed", job.FailedRows))
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		finish(models.ImportFailed, "failed to read the uploaded file")
+		return
+	}
+	rows, err := newRowReader(file, job.Format)
+	if err != nil {
+		finish(models.ImportFailed, err.Error())
+		return
+	}
+
+	chunkSize := min(importChunkSize, config.AppConfig.ShortenBatchLimit)
+	chunk := make([]importRow, 0, chunkSize)
+	create := func() {
+		reqs := make([]dtos.URLRequest, len(chunk))
+		for i, row := range chunk {
+			reqs[i] = dtos.URLRequest{
+				OriginalURL: row.row.Destination,
+				CustomAlias: row.row.Alias,
+				Tags:        row.row.Tags,
+				ExpiresAt:   row.row.ExpiresAt,
+				WorkspaceID: job.WorkspaceID,
+			}
+		}
+
+		results, err := s.links.ShortenBatch(ctx, reqs)
+		for i, row := range chunk {
+			switch {
+			case err != nil:
+				fail(row, err)
+			case results[i].Err != nil:
+				fail(row, results[i].Err)
+			default:
+				job.SucceededRows++
+			}
+		}
+		chunk = chunk[:0]
+		s.jobs.UpdateProgress(ctx, job)
+	}
+
+	for {
+		row, err := rows.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			if len(chunk) > 0 {
+				create()
+			}
+			finish(models.ImportFailed, err.Error())
+			return
+		}
+
+		job.TotalRows++
+		if row.err != nil {
+			fail(row, row.err)
+			continue
+		}
+
+		chunk = append(chunk, row)
+		if len(chunk) < chunkSize {
+			continue
+		}
+
+		create()
+
+		select {
+		case <-stopImports:
+			finish(models.ImportFailed, "interrupted by a server restart after line "+strconv.Itoa(row.line)+", import the remaining rows again")
+			return
+		default:
+		}
+	}
+
+	if len(chunk) > 0 {
+		create()
+	}
+	finish(models.ImportCompleted, "")
+}
+
+// GetImportJob returns one of the caller's import jobs.
+func (s *importExportServiceImpl) GetImportJob(ctx *context.Context, id string) (*models.ImportJob, error) {
+
+	if !ctx.IsAuthenticated() {
+		return nil, ErrUnauthorized
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrImportJobNotFound
+	}
+
+	job, err := s.jobs.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil || job.OwnerID != ctx.OwnerID {
+		return nil, ErrImportJobNotFound
+	}
+	return job, nil
+}
+
+// ExportURLs calls fn for each of the caller's links, or each link of the
+// workspace, without loading them all at once.
+func (s *importExportServiceImpl) ExportURLs(ctx *context.Context, workspaceID uuid.UUID, fn func(*models.URL) error) error {
+
+	if !ctx.IsAuthenticated() {
+		return ErrUnauthorized
+	}
+
+	scope := repository.URLScope{OwnerID: ctx.OwnerID}
+	if workspaceID != uuid.Nil {
+		if err := authorizeWorkspace(ctx, s.workspaces, workspaceID, actionRead); err != nil {
+			return err
+		}
+		scope = repository.URLScope{WorkspaceID: workspaceID}
+	}
+
+	return s.urls.EachURL(ctx, scope, fn)
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mohan7-code/url-shortener/dtos"
+	"github.com/mohan7-code/url-shortener/models"
+)
+
+// maxNDJSONLine bounds a single NDJSON record.
+const maxNDJSONLine = 1 << 20
+
+// importRow is one parsed row of an import file. Err is set when the row
+// itself could not be read; the rest of the file is still imported.
+type importRow struct {
+	line int
+	row  dtos.ImportRow
+	err  error
+}
+
+// rowReader yields the rows of an import file until io.EOF. Any other error
+// it returns stops the import.
+type rowReader interface {
+	next() (importRow, error)
+}
+
+func newRowReader(r io.Reader, format string) (rowReader, error) {
+	switch format {
+	case models.FormatCSV:
+		return newCSVRowReader(r)
+	case models.FormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLine)
+		return &ndjsonRowReader{scanner: scanner}, nil
+	}
+	return nil, ErrInvalidImportFormat
+}
+
+// csvRowReader reads a CSV file whose first record names the columns:
+// destination (required), alias, tags and expires_at, in any order. Tags
+// are separated by semicolons and expires_at is RFC 3339. Other columns are
+// ignored, so an export can be read as is; its alias column holds every
+// link's code, so on the same domain those rows fail with ErrAliasTaken
+// unless the column is removed.
+type csvRowReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVRowReader(r io.Reader) (*csvRowReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, seen := columns[name]; !seen {
+			columns[name] = i
+		}
+	}
+	if _, ok := columns["destination"]; !ok {
+		return nil, errors.New("missing destination column")
+	}
+	return &csvRowReader{reader: reader, columns: columns}, nil
+}
+
+func (r *csvRowReader) next() (importRow, error) {
+	record, err := r.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return importRow{line: parseErr.Line, err: parseErr.Err}, nil
+		}
+		return importRow{}, err
+	}
+
+	line, _ := r.reader.FieldPos(0)
+	field := func(name string) string {
+		i, ok := r.columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	row := dtos.ImportRow{
+		Destination: field("destination"),
+		Alias:       field("alias"),
+	}
+	for _, tag := range strings.Split(field("tags"), ";") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			row.Tags = append(row.Tags, tag)
+		}
+	}
+	if expiresAt := field("expires_at"); expiresAt != "" {
+		parsed, err := time.Parse(time.RFC3339, expiresAt)
+		if err != nil {
+			return importRow{line: line, row: row, err: errors.New("invalid expires_at, use RFC 3339")}, nil
+		}
+		row.ExpiresAt = &parsed
+	}
+	return importRow{line: line, row: row}, nil
+}
+
+// ndjsonRowReader reads one dtos.ImportRow object per line. Blank lines are
+// skipped.
+type ndjsonRowReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (r *ndjsonRowReader) next() (importRow, error) {
+	for r.scanner.Scan() {
+		r.line++
+		raw := strings.TrimSpace(r.scanner.Text())
+		if raw == "" {
+			continue
+		}
+
+		var row dtos.ImportRow
+		if err := json.Unmarshal([]byte(raw), &row); err != nil {
+			return importRow{line: r.line, err: errors.New("invalid JSON")}, nil
+		}
+		return importRow{line: r.line, row: row}, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return importRow{}, fmt.Errorf("failed to read line %d: %w", r.line+1, err)
+	}
+	return importRow{}, io.EOF
+}
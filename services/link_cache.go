@@ -0,0 +1,138 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mohan7-code/url-shortener/models"
+	"github.com/mohan7-code/url-shortener/repository"
+	"github.com/mohan7-code/url-shortener/utils/cache"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	"github.com/redis/go-redis/v9"
+)
+
+// cachedLink is the value stored in Redis for both directions of a link:
+// under its code for redirects, and under its destination for ShortenURL.
+// It keeps the link ID so cache hits can still record clicks, the
+// moderation status so links under review keep their warning page, the
+// targeting rules and variants so every hit is still routed per visitor, and
+// the redirect type and expiry so cache hits send the same status and cache
+// headers.
+type cachedLink struct {
+	ID          uuid.UUID `json:"id"`
+	ShortCode   string    `json:"short_code"`
+	Domain      string    `json:"domain,omitempty"`
+	OriginalURL string    `json:"original_url"`
+	Status      string    `json:"status,omitempty"`
+
+	RedirectType int        `json:"redirect_type,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+
+	Targeting models.TargetingRules `json:"targeting,omitempty"`
+	Variants  models.Variants       `json:"variants,omitempty"`
+}
+
+func (l *cachedLink) toURL() *models.URL {
+	url := &models.URL{
+		ID:          l.ID,
+		ShortCode:   l.ShortCode,
+		OriginalURL: l.OriginalURL,
+		Status:      l.Status,
+		Targeting:   l.Targeting,
+		Variants:    l.Variants,
+
+		RedirectType: l.RedirectType,
+		ExpiresAt:    l.ExpiresAt,
+	}
+	if url.Status == "" {
+		url.Status = models.StatusActive
+	}
+	if url.RedirectType == 0 {
+		url.RedirectType = http.StatusFound
+	}
+	if l.Domain != "" {
+		domain := l.Domain
+		url.Domain = &domain
+	}
+	return url
+}
+
+// codeCacheKey is the redirect key. Codes on the default domain keep the
+// bare code as their key.
+func codeCacheKey(domain, shortCode string) string {
+	if domain == "" {
+		return shortCode
+	}
+	return domain + "/" + shortCode
+}
+
+// urlCacheKey is the reverse key used to find an existing code for a
+// destination. Links are only reused within one owner's or workspace's
+// links on the same domain, so the key carries all three.
+func urlCacheKey(scope repository.URLScope, domain, originalURL string) string {
+	owner := "owner:" + scope.OwnerID.String()
+	if scope.WorkspaceID != uuid.Nil {
+		owner = "workspace:" + scope.WorkspaceID.String()
+	}
+	return "dest:" + owner + ":" + domain + ":" + originalURL
+}
+
+func getCachedLink(ctx *context.Context, key string) (*cachedLink, bool) {
+	raw, err := cache.New().Client.Get(ctx, key).Bytes()
+	if err != nil || len(raw) == 0 {
+		return nil, false
+	}
+
+	var link cachedLink
+	if err := json.Unmarshal(raw, &link); err != nil || link.ID == uuid.Nil {
+		return nil, false
+	}
+	return &link, true
+}
+
+func setCachedLink(ctx *context.Context, key string, url *models.URL, ttl time.Duration) error {
+	raw, err := encodeCachedLink(url)
+	if err != nil {
+		return err
+	}
+	return cache.New().Client.Set(ctx, key, raw, ttl).Err()
+}
+
+// setCachedLinks caches newly created links under all their keys in one
+// round trip.
+func setCachedLinks(ctx *context.Context, links []*pendingLink) error {
+	_, err := cache.New().Client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, link := range links {
+			ttl := cacheTTL(link.url)
+			if ttl <= 0 {
+				continue
+			}
+			raw, err := encodeCachedLink(link.url)
+			if err != nil {
+				return err
+			}
+			for _, key := range link.cacheKeys() {
+				pipe.Set(ctx, key, raw, ttl)
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+func encodeCachedLink(url *models.URL) ([]byte, error) {
+	return json.Marshal(&cachedLink{
+		ID:          url.ID,
+		ShortCode:   url.ShortCode,
+		Domain:      url.DomainName(),
+		OriginalURL: url.OriginalURL,
+		Status:      url.Status,
+		Targeting:   url.Targeting,
+		Variants:    url.Variants,
+
+		RedirectType: url.RedirectType,
+		ExpiresAt:    url.ExpiresAt,
+	})
+}
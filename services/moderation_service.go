@@ -0,0 +1,262 @@
+package service
+
+import (
+	"math"
+	"strings"
+
+	"github.com/mohan7-code/url-shortener/config"
+	"github.com/mohan7-code/url-shortener/dtos"
+	"github.com/mohan7-code/url-shortener/models"
+	"github.com/mohan7-code/url-shortener/repository"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	helper "github.com/mohan7-code/url-shortener/utils/helpers"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultReportsLimit = 50
+	maxReportsLimit     = 200
+	maxReportDetails    = 2000
+)
+
+type IModerationService interface {
+	ReportLink(ctx *context.Context, ref dtos.LinkRef, req *dtos.ReportRequest) error
+	ListReports(ctx *context.Context, query *dtos.ReportQuery) (*dtos.ListResponse, error)
+	SetLinkStatus(ctx *context.Context, ref dtos.LinkRef, req *dtos.ModerationRequest) (*models.URL, error)
+	ListAuditTrail(ctx *context.Context, ref dtos.LinkRef) ([]*models.ModerationAction, error)
+}
+
+type moderationServiceImpl struct {
+	urls       repository.IURLRepository
+	moderation repository.IModerationRepository
+}
+
+func NewModerationService() IModerationService {
+	return &moderationServiceImpl{
+		urls:       repository.NewURLRepository(),
+		moderation: repository.NewModerationRepository(),
+	}
+}
+
+// ReportLink files an abuse report from the calling client. A client with an
+// open report against the link is not counted twice. When the link reaches
+// ReportReviewThreshold open reports admins are alerted once, until an admin
+// resolves the reports. Its status is only changed by an admin, so reports
+// alone cannot take a link down.
+func (s *moderationServiceImpl) ReportLink(ctx *context.Context, ref dtos.LinkRef, req *dtos.ReportRequest) error {
+
+	reason := strings.ToLower(strings.TrimSpace(req.Reason))
+	if !isValidReportReason(reason) {
+		return ErrInvalidReason
+	}
+
+	details := strings.TrimSpace(req.Details)
+	if len(details) > maxReportDetails {
+		details = strings.ToValidUTF8(details[:maxReportDetails], "")
+	}
+
+	url, err := s.getLink(ctx, ref)
+	if err != nil {
+		return err
+	}
+
+	ipHash := helper.HashIP(ctx.ClientIP(), config.AppConfig.IPHashSalt)
+
+	duplicate, err := s.moderation.HasOpenReport(ctx, url.ID.String(), ipHash)
+	if err != nil {
+		return err
+	}
+	if duplicate {
+		ctx.Log.Info("duplicate link report ignored", zap.String("short_code", url.ShortCode))
+		return nil
+	}
+
+	report := &models.LinkReport{
+		URLID:          url.ID,
+		Reason:         reason,
+		Details:        details,
+		ReporterIPHash: ipHash,
+	}
+	if err := s.moderation.CreateReport(ctx, report); err != nil {
+		return err
+	}
+
+	ctx.Log.Info("link reported", zap.String("short_code", url.ShortCode), zap.String("reason", reason))
+
+	if url.Status != models.StatusActive {
+		return nil
+	}
+
+	open, err := s.moderation.CountOpenReports(ctx, url.ID.String())
+	if err != nil {
+		return err
+	}
+	// reports filed together can jump past the threshold, so the first one
+	// at or above it claims the alert
+	if open >= int64(config.AppConfig.ReportReviewThreshold) && claimReportAlert(ctx, url.ID.String()) {
+		alertAdmins(ctx, url, open)
+	}
+	return nil
+}
+
+// ListReports returns open reports, or resolved ones, newest first.
+func (s *moderationServiceImpl) ListReports(ctx *context.Context, query *dtos.ReportQuery) (*dtos.ListResponse, error) {
+
+	if !ctx.IsAdmin {
+		return nil, ErrAdminOnly
+	}
+
+	page, limit := query.Page, query.Limit
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = defaultReportsLimit
+	}
+	limit = min(limit, maxReportsLimit)
+
+	reports, total, err := s.moderation.ListReports(ctx, !query.Resolved, limit, (page-1)*limit)
+	if err != nil {
+		return nil, err
+	}
+	if reports == nil {
+		reports = []*models.ReportWithLink{}
+	}
+
+	return &dtos.ListResponse{
+		Data:       reports,
+		TotalCount: total,
+		Pages:      int(math.Ceil(float64(total) / float64(limit))),
+	}, nil
+}
+
+// SetLinkStatus moves any link to a new moderation status on behalf of an
+// admin. Its open reports are resolved with the new status, except when the
+// link is only put under review.
+func (s *moderationServiceImpl) SetLinkStatus(ctx *context.Context, ref dtos.LinkRef, req *dtos.ModerationRequest) (*models.URL, error) {
+
+	if !ctx.IsAdmin {
+		return nil, ErrAdminOnly
+	}
+
+	status := strings.ToLower(strings.TrimSpace(req.Status))
+	if !isValidLinkStatus(status) {
+		return nil, ErrInvalidStatus
+	}
+
+	url, err := s.getLink(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	if url.Status == status {
+		return nil, ErrStatusUnchanged
+	}
+
+	actorID := ctx.OwnerID
+	action := &models.ModerationAction{
+		URLID:      url.ID,
+		ShortCode:  url.ShortCode,
+		Domain:     url.Domain,
+		ActorID:    &actorID,
+		FromStatus: url.Status,
+		ToStatus:   status,
+		Note:       strings.TrimSpace(req.Note),
+	}
+
+	applied, err := s.apply(ctx, url, action, status != models.StatusUnderReview)
+	if err != nil {
+		return nil, err
+	}
+	if !applied {
+		// another moderator changed the link first
+		return nil, ErrStatusUnchanged
+	}
+
+	url.Status = status
+	return url, nil
+}
+
+// ListAuditTrail returns every moderation action taken on the link, oldest
+// first. It also answers for codes whose link was archived or deleted.
+func (s *moderationServiceImpl) ListAuditTrail(ctx *context.Context, ref dtos.LinkRef) ([]*models.ModerationAction, error) {
+
+	if !ctx.IsAdmin {
+		return nil, ErrAdminOnly
+	}
+
+	domain := normalizeHost(ref.Domain)
+	url, err := s.urls.GetUrlByShortCode(ctx, domain, ref.ShortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	// a live link has its own trail; once it is archived or deleted the
+	// trail is found by the code it had
+	var actions []*models.ModerationAction
+	if url != nil {
+		actions, err = s.moderation.ListActions(ctx, url.ID.String())
+	} else {
+		actions, err = s.moderation.ListActionsByCode(ctx, domain, ref.ShortCode)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if url == nil && len(actions) == 0 {
+		return nil, ErrShortCodeNotFound
+	}
+	if actions == nil {
+		actions = []*models.ModerationAction{}
+	}
+	return actions, nil
+}
+
+// apply writes the status change and its audit entry, then drops the link
+// from the cache so redirects pick the new status up immediately.
+func (s *moderationServiceImpl) apply(ctx *context.Context, url *models.URL, action *models.ModerationAction, resolveReports bool) (bool, error) {
+	applied, err := s.moderation.ApplyStatus(ctx, action, resolveReports)
+	if err != nil {
+		return false, err
+	}
+	if !applied {
+		return false, nil
+	}
+
+	invalidateCache(ctx, url)
+	if resolveReports {
+		clearReportAlert(ctx, url.ID.String())
+	}
+
+	ctx.Log.Info("link status changed",
+		zap.String("short_code", url.ShortCode),
+		zap.String("from", action.FromStatus),
+		zap.String("to", action.ToStatus))
+	return true, nil
+}
+
+// getLink loads a link regardless of who owns it.
+func (s *moderationServiceImpl) getLink(ctx *context.Context, ref dtos.LinkRef) (*models.URL, error) {
+	url, err := s.urls.GetUrlByShortCode(ctx, normalizeHost(ref.Domain), ref.ShortCode)
+	if err != nil {
+		return nil, err
+	}
+	if url == nil {
+		return nil, ErrShortCodeNotFound
+	}
+	return url, nil
+}
+
+func isValidReportReason(reason string) bool {
+	switch reason {
+	case models.ReportPhishing, models.ReportMalware, models.ReportSpam, models.ReportOther:
+		return true
+	}
+	return false
+}
+
+func isValidLinkStatus(status string) bool {
+	switch status {
+	case models.StatusActive, models.StatusDisabled, models.StatusUnderReview:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"time"
+
+	"github.com/mohan7-code/url-shortener/config"
+	"github.com/mohan7-code/url-shortener/dtos"
+	"github.com/mohan7-code/url-shortener/models"
+	"github.com/mohan7-code/url-shortener/utils/cache"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcrypt ignores everything past 72 bytes, so longer passwords are refused
+// rather than silently truncated.
+const (
+	minLinkPasswordLen = 4
+	maxLinkPasswordLen = 72
+)
+
+// LockoutError is returned while a protected link refuses password attempts.
+type LockoutError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockoutError) Error() string {
+	return ErrTooManyAttempts.Error()
+}
+
+func (e *LockoutError) Unwrap() error {
+	return ErrTooManyAttempts
+}
+
+// UnlockURL resolves a protected code like GetOriginalURL, but only returns
+// the link once password matches. Attempts are counted per code and client
+// IP, and after PasswordMaxAttempts wrong ones the code refuses that client
+// until the PasswordLockout window ends. A second counter per code caps the
+// guesses of all clients together at PasswordMaxCodeAttempts, so an attacker
+// cannot multiply the limit by spreading guesses over many IPs. Each attempt
+// is reserved before the password is compared, so parallel requests cannot
+// get extra guesses.
+func (s *urlServiceImpl) UnlockURL(ctx *context.Context, host, shortCode, password string, click *dtos.ClickInfo) (*models.URL, error) {
+
+	domain := resolveDomain(ctx, s.domains, host)
+
+	url, err := s.loadLink(ctx, domain, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if url.IsProtected() {
+		clientKey := passwordAttemptsKey(domain, shortCode, ctx.ClientIP())
+		codeKey := passwordCodeAttemptsKey(domain, shortCode)
+
+		clientReserved, err := reserveAttempt(ctx, clientKey, config.AppConfig.PasswordMaxAttempts)
+		if err != nil {
+			return nil, err
+		}
+		codeReserved, err := reserveAttempt(ctx, codeKey, config.AppConfig.PasswordMaxCodeAttempts)
+		if err != nil {
+			// the client never got to guess, so its own attempt is returned
+			if clientReserved {
+				releaseAttempt(ctx, clientKey)
+			}
+			return nil, err
+		}
+
+		if bcrypt.CompareHashAndPassword([]byte(*url.PasswordHash), []byte(password)) != nil {
+			ctx.Log.Info("wrong password for protected link", zap.String("short_code", shortCode))
+			return nil, ErrWrongPassword
+		}
+		if clientReserved {
+			releaseAttempt(ctx, clientKey)
+		}
+		if codeReserved {
+			releaseAttempt(ctx, codeKey)
+		}
+	}
+
+	chooseDestination(url, click)
+	if url.Status == models.StatusActive {
+		if err := s.countClick(ctx, url, click); err != nil {
+			return nil, err
+		}
+	}
+	return url, nil
+}
+
+func hashLinkPassword(password string) (*string, error) {
+	if len(password) < minLinkPasswordLen || len(password) > maxLinkPasswordLen {
+		return nil, ErrInvalidPassword
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	encoded := string(hash)
+	return &encoded, nil
+}
+
+func passwordAttemptsKey(domain, shortCode, clientIP string) string {
+	return passwordCodeAttemptsKey(domain, shortCode) + ":" + clientIP
+}
+
+func passwordCodeAttemptsKey(domain, shortCode string) string {
+	return "unlock:" + codeCacheKey(domain, shortCode)
+}
+
+// reserveAttemptScript counts an attempt and returns {attempts, milliseconds
+// left in the window}. The window starts at the first attempt and is not
+// extended by later ones.
+var reserveAttemptScript = redis.NewScript(`
+local attempts = redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[1], 'NX')
+return {attempts, redis.call('PTTL', KEYS[1])}
+`)
+
+// reserveAttempt takes one of the limit attempts counted under key, returning
+// a LockoutError once they are used up. It reports whether an attempt was
+// reserved; like the rate limiter it lets requests through unreserved when
+// Redis is unavailable.
+func reserveAttempt(ctx *context.Context, key string, limit int) (bool, error) {
+	window := config.AppConfig.PasswordLockout
+	result, err := reserveAttemptScript.Run(ctx, cache.New().Client, []string{key}, window.Milliseconds()).Int64Slice()
+	if err != nil || len(result) != 2 {
+		ctx.Log.Warn("failed to reserve password attempt", zap.String("key", key), zap.Error(err))
+		return false, nil
+	}
+
+	if result[0] > int64(limit) {
+		retryAfter := time.Duration(result[1]) * time.Millisecond
+		if retryAfter <= 0 {
+			retryAfter = window
+		}
+		return false, &LockoutError{RetryAfter: retryAfter}
+	}
+	return true, nil
+}
+
+// releaseAttempt gives back the attempt of a correct password, so only wrong
+// ones count towards the lockout.
+func releaseAttempt(ctx *context.Context, key string) {
+	if err := cache.New().Client.Decr(ctx, key).Err(); err != nil {
+		ctx.Log.Warn("failed to release password attempt", zap.String("key", key), zap.Error(err))
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"bytes"
+	"image/color"
+	"strings"
+
+	"github.com/mohan7-code/url-shortener/dtos"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	"github.com/mohan7-code/url-shortener/utils/qrcode"
+	"go.uber.org/zap"
+)
+
+const (
+	qrFormatPNG = "png"
+	qrFormatSVG = "svg"
+
+	defaultQRSize   = 256
+	minQRSize       = 64
+	maxQRSize       = 2048
+	defaultQRMargin = 4 // the quiet zone the QR specification asks for
+	maxQRMargin     = 32
+)
+
+// GetQRCode renders the link's full short URL as a QR code and returns the
+// image with its content type.
+func (s *urlServiceImpl) GetQRCode(ctx *context.Context, ref dtos.LinkRef, query *dtos.QRQuery) ([]byte, string, error) {
+
+	format := strings.ToLower(query.Format)
+	if format == "" {
+		format = qrFormatPNG
+	}
+	if format != qrFormatPNG && format != qrFormatSVG {
+		return nil, "", ErrInvalidQRFormat
+	}
+
+	opts := qrcode.Options{Size: query.Size, Margin: defaultQRMargin}
+	if opts.Size == 0 {
+		opts.Size = defaultQRSize
+	}
+	if opts.Size < minQRSize || opts.Size > maxQRSize {
+		return nil, "", ErrInvalidQRSize
+	}
+	if query.Margin != nil {
+		opts.Margin = *query.Margin
+	}
+	if opts.Margin < 0 || opts.Margin > maxQRMargin {
+		return nil, "", ErrInvalidQRMargin
+	}
+
+	level := qrcode.Medium
+	if query.Level != "" {
+		var ok bool
+		if level, ok = qrcode.ParseLevel(query.Level); !ok {
+			return nil, "", ErrInvalidQRLevel
+		}
+	}
+
+	var ok bool
+	if opts.Foreground, ok = parseQRColor(query.Foreground, "000000"); !ok {
+		return nil, "", ErrInvalidQRColor
+	}
+	if opts.Background, ok = parseQRColor(query.Background, "ffffff"); !ok {
+		return nil, "", ErrInvalidQRColor
+	}
+
+	url, err := s.getAuthorizedURL(ctx, ref, actionRead)
+	if err != nil {
+		return nil, "", err
+	}
+
+	code, err := qrcode.Encode([]byte(ShortURL(url)), level)
+	if err != nil {
+		ctx.Log.Error("failed to encode qr code", zap.String("short_code", url.ShortCode), zap.Error(err))
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	contentType := "image/png"
+	if format == qrFormatSVG {
+		contentType = "image/svg+xml"
+		err = code.SVG(&buf, opts)
+	} else {
+		err = code.PNG(&buf, opts)
+	}
+	if err != nil {
+		ctx.Log.Error("failed to render qr code", zap.String("short_code", url.ShortCode), zap.Error(err))
+		return nil, "", err
+	}
+
+	return buf.Bytes(), contentType, nil
+}
+
+func parseQRColor(value, fallback string) (color.NRGBA, bool) {
+	if value == "" {
+		value = fallback
+	}
+	return qrcode.ParseColor(value)
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/mohan7-code/url-shortener/config"
+	"github.com/mohan7-code/url-shortener/models"
+	"github.com/mohan7-code/url-shortener/utils/cache"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	"go.uber.org/zap"
+)
+
+const (
+	reportAlertTimeout = 5 * time.Second
+	// reportAlertTTL bounds how long an alert suppresses the next one, so a
+	// link left unreviewed is raised again by the next report after a week.
+	reportAlertTTL = 7 * 24 * time.Hour
+)
+
+var reportAlertClient = &http.Client{Timeout: reportAlertTimeout}
+
+// reportAlert is the JSON body posted to REPORT_ALERT_WEBHOOK.
+type reportAlert struct {
+	Event       string  `json:"event"`
+	ShortCode   string  `json:"short_code"`
+	Domain      *string `json:"domain,omitempty"`
+	OriginalURL string  `json:"original_url"`
+	OpenReports int64   `json:"open_reports"`
+}
+
+// alertAdmins tells admins that a link has collected enough open reports to
+// need a decision. It always logs a warning and, when REPORT_ALERT_WEBHOOK
+// is set, posts the alert there in the background.
+func alertAdmins(ctx *context.Context, url *models.URL, open int64) {
+	log := ctx.Log.With(zap.String("short_code", url.ShortCode), zap.Int64("open_reports", open))
+	log.Warn("link needs moderation review")
+
+	webhook := config.AppConfig.ReportAlertWebhook
+	if webhook == "" {
+		return
+	}
+
+	body, err := json.Marshal(reportAlert{
+		Event:       "link_reported",
+		ShortCode:   url.ShortCode,
+		Domain:      url.Domain,
+		OriginalURL: url.OriginalURL,
+		OpenReports: open,
+	})
+	if err != nil {
+		log.Error("failed to encode report alert", zap.Error(err))
+		return
+	}
+
+	go func() {
+		resp, err := reportAlertClient.Post(webhook, "application/json", bytes.NewReader(body))
+		if err != nil {
+			log.Error("failed to send report alert", zap.Error(err))
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= http.StatusMultipleChoices {
+			log.Error("report alert webhook refused the alert", zap.Int("status", resp.StatusCode))
+		}
+	}()
+}
+
+func reportAlertKey(urlID string) string {
+	return "report-alert:" + urlID
+}
+
+// claimReportAlert reports whether the caller is the first to see the link's
+// open reports reach the threshold, so concurrent reports that jump past it
+// raise one alert between them. It fails open: without Redis an alert may be
+// sent twice, but never lost.
+func claimReportAlert(ctx *context.Context, urlID string) bool {
+	claimed, err := cache.New().Client.SetNX(ctx, reportAlertKey(urlID), 1, reportAlertTTL).Result()
+	if err != nil {
+		ctx.Log.Warn("failed to claim report alert", zap.String("url_id", urlID), zap.Error(err))
+		return true
+	}
+	return claimed
+}
+
+// clearReportAlert re-arms the alert once a link's open reports are
+// resolved, so the next wave of reports is raised again.
+func clearReportAlert(ctx *context.Context, urlID string) {
+	if err := cache.New().Client.Del(ctx, reportAlertKey(urlID)).Err(); err != nil {
+		ctx.Log.Warn("failed to clear report alert", zap.String("url_id", urlID), zap.Error(err))
+	}
+}
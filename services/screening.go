@@ -0,0 +1,172 @@
+package service
+
+import (
+	stdctx "context"
+	"net"
+	"net/netip"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mohan7-code/url-shortener/config"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	"go.uber.org/zap"
+)
+
+// Reasons a destination can be rejected by screenURL.
+const (
+	ReasonLoopback         = "loopback_address"
+	ReasonPrivateAddress   = "private_address"
+	ReasonLinkLocal        = "link_local_address"
+	ReasonSelfReferential  = "self_referential"
+	ReasonChainedShortener = "chained_shortener"
+	ReasonBlocklisted      = "blocklisted"
+)
+
+const screeningDNSTimeout = 2 * time.Second
+
+// carrier-grade NAT space, which netip does not count as private
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// ScreeningError is returned when a destination is refused. Reason is one
+// of the Reason* codes and is meant for clients; Detail is for humans.
+type ScreeningError struct {
+	Reason string
+	Detail string
+}
+
+func (e *ScreeningError) Error() string {
+	return "destination rejected: " + e.Detail
+}
+
+func (e *ScreeningError) Unwrap() error {
+	return ErrUnsafeURL
+}
+
+// screenURL checks a normalized destination before it is stored. It refuses
+// links back to this service, to other URL shorteners, to blocklisted
+// domains or URLs, and to hosts that are or resolve to internal addresses.
+func (s *urlServiceImpl) screenURL(ctx *context.Context, originalURL string) error {
+	u, err := neturl.Parse(originalURL)
+	if err != nil {
+		return ErrInvalidURL
+	}
+	// "localhost." and "sho.rt." name the same hosts as without the dot
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+
+	if host == defaultShortHost() {
+		return s.reject(ctx, originalURL, ReasonSelfReferential, "links to this shortener would redirect to themselves")
+	}
+	domain, err := s.domains.GetByHostname(ctx, host)
+	if err != nil {
+		return err
+	}
+	if domain != nil {
+		return s.reject(ctx, originalURL, ReasonSelfReferential, "links to a branded domain of this shortener would redirect to themselves")
+	}
+
+	if name, ok := matchDomain(host, shortenerDomains()); ok {
+		return s.reject(ctx, originalURL, ReasonChainedShortener, name+" is a URL shortener, shorten the final destination instead")
+	}
+
+	if entries := activeBlocklist.Load(); entries != nil {
+		if entry, ok := entries.blocked(host, originalURL); ok {
+			return s.reject(ctx, originalURL, ReasonBlocklisted, "destination matches blocklist entry "+entry)
+		}
+	}
+
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return s.reject(ctx, originalURL, ReasonLoopback, "destination is a loopback host")
+	}
+
+	addrs := []netip.Addr{}
+	if addr, ok := parseHostIP(host); ok {
+		addrs = append(addrs, addr)
+	} else if config.AppConfig.ScreenResolveDNS {
+		resolveCtx, cancel := stdctx.WithTimeout(ctx, screeningDNSTimeout)
+		defer cancel()
+
+		resolved, err := net.DefaultResolver.LookupNetIP(resolveCtx, "ip", host)
+		if err != nil {
+			// unresolvable hosts cannot reach anything internal either
+			ctx.Log.Info("could not resolve destination host", zap.String("host", host), zap.Error(err))
+		}
+		addrs = append(addrs, resolved...)
+	}
+
+	for _, addr := range addrs {
+		if reason, ok := classifyAddress(addr); ok {
+			return s.reject(ctx, originalURL, reason, "destination resolves to the internal address "+addr.String())
+		}
+	}
+
+	return nil
+}
+
+func (s *urlServiceImpl) reject(ctx *context.Context, originalURL, reason, detail string) error {
+	ctx.Log.Warn("destination rejected", zap.String("url", originalURL), zap.String("reason", reason))
+	return &ScreeningError{Reason: reason, Detail: detail}
+}
+
+// classifyAddress returns the rejection reason for addresses that are not
+// publicly routable.
+func classifyAddress(addr netip.Addr) (string, bool) {
+	addr = addr.Unmap()
+	switch {
+	case addr.IsLoopback(), addr.IsUnspecified():
+		return ReasonLoopback, true
+	case addr.IsLinkLocalUnicast(), addr.IsLinkLocalMulticast():
+		return ReasonLinkLocal, true
+	case addr.IsPrivate(), sharedAddressSpace.Contains(addr), addr.IsMulticast():
+		return ReasonPrivateAddress, true
+	}
+	return "", false
+}
+
+// parseHostIP parses an IP literal host. Besides the usual forms it accepts
+// the shorthand IPv4 notations browsers still honour, such as 2130706433,
+// 0x7f.1 or 0177.0.0.1, so they cannot be used to hide 127.0.0.1.
+func parseHostIP(host string) (netip.Addr, bool) {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return addr, true
+	}
+
+	parts := strings.Split(host, ".")
+	if len(parts) > 4 {
+		return netip.Addr{}, false
+	}
+
+	values := make([]uint64, len(parts))
+	for i, part := range parts {
+		v, err := strconv.ParseUint(part, 0, 32)
+		if err != nil {
+			return netip.Addr{}, false
+		}
+		values[i] = v
+	}
+
+	// the last part fills all the remaining bytes
+	var ip uint64
+	for i, v := range values[:len(values)-1] {
+		if v > 0xff {
+			return netip.Addr{}, false
+		}
+		ip |= v << (24 - 8*i)
+	}
+	last := values[len(values)-1]
+	if last >= 1<<(8*(5-len(values))) {
+		return netip.Addr{}, false
+	}
+	ip |= last
+
+	return netip.AddrFrom4([4]byte{byte(ip >> 24), byte(ip >> 16), byte(ip >> 8), byte(ip)}), true
+}
+
+func shortenerDomains() map[string]bool {
+	domains := make(map[string]bool, len(config.AppConfig.ShortenerDomains))
+	for _, name := range config.AppConfig.ShortenerDomains {
+		domains[strings.ToLower(name)] = true
+	}
+	return domains
+}
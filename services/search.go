@@ -0,0 +1,141 @@
+package service
+
+import (
+	"html"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/mohan7-code/url-shortener/dtos"
+	"github.com/mohan7-code/url-shortener/models"
+	"github.com/mohan7-code/url-shortener/repository"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	maxSearchLength    = 200
+	maxTitleLength     = 200
+)
+
+// SearchURLs returns the caller's links, or the workspace's, that best match
+// query.Q, with the matches in each field marked for display. Q takes web
+// search syntax: quoted phrases, OR and -word.
+func (s *urlServiceImpl) SearchURLs(ctx *context.Context, query *dtos.SearchQuery) ([]*models.SearchHit, error) {
+
+	if !ctx.IsAuthenticated() {
+		return nil, ErrUnauthorized
+	}
+
+	q := strings.TrimSpace(query.Q)
+	if q == "" || utf8.RuneCountInString(q) > maxSearchLength {
+		return nil, ErrInvalidSearch
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	limit = min(limit, maxSearchLimit)
+
+	scope := repository.URLScope{OwnerID: ctx.OwnerID}
+	if query.WorkspaceID != uuid.Nil {
+		if err := authorizeWorkspace(ctx, s.workspaces, query.WorkspaceID, actionRead); err != nil {
+			return nil, err
+		}
+		scope = repository.URLScope{WorkspaceID: query.WorkspaceID}
+	}
+
+	hits, err := s.repo.SearchURLs(ctx, scope, q, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := searchTerms(q)
+	for _, hit := range hits {
+		hit.Highlights = highlight(hit, terms)
+	}
+	return hits, nil
+}
+
+// searchTerms builds a case-insensitive pattern matching the words of q
+// that should be highlighted, leaving out web search operators and excluded
+// words. It is nil when there are none.
+func searchTerms(q string) *regexp.Regexp {
+	var words []string
+	for _, word := range strings.Fields(strings.ReplaceAll(q, `"`, " ")) {
+		if strings.HasPrefix(word, "-") || strings.EqualFold(word, "or") {
+			continue
+		}
+		words = append(words, regexp.QuoteMeta(word))
+	}
+	if len(words) == 0 {
+		return nil
+	}
+
+	// longer words first, so a word is not cut short by one it starts with
+	slices.SortFunc(words, func(a, b string) int { return len(b) - len(a) })
+	return regexp.MustCompile("(?i)" + strings.Join(words, "|"))
+}
+
+func highlight(hit *models.SearchHit, terms *regexp.Regexp) models.SearchHighlights {
+	highlights := models.SearchHighlights{
+		ShortCode:   markMatches(hit.ShortCode, terms),
+		OriginalURL: markMatches(hit.OriginalURL, terms),
+	}
+
+	// ts_headline only marks whole words; fragments matched by trigram are
+	// marked here instead
+	if strings.Contains(hit.TitleHeadline, models.HeadlineStart) {
+		highlights.Title = strings.NewReplacer(models.HeadlineStart, "<mark>", models.HeadlineStop, "</mark>").
+			Replace(html.EscapeString(hit.TitleHeadline))
+	} else {
+		highlights.Title = markMatches(hit.Title, terms)
+	}
+
+	for _, tag := range hit.Tags {
+		if marked := markMatches(tag, terms); marked != "" {
+			highlights.Tags = append(highlights.Tags, marked)
+		}
+	}
+	return highlights
+}
+
+// markMatches HTML escapes s and wraps each match of terms in <mark>. It
+// returns "" when nothing matches.
+func markMatches(s string, terms *regexp.Regexp) string {
+	if terms == nil {
+		return ""
+	}
+	matches := terms.FindAllStringIndex(s, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		b.WriteString(html.EscapeString(s[last:m[0]]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(s[m[0]:m[1]]))
+		b.WriteString("</mark>")
+		last = m[1]
+	}
+	b.WriteString(html.EscapeString(s[last:]))
+	return b.String()
+}
+
+// cleanTitle trims a link title and checks its length. Control characters
+// are refused, which also keeps the search headline delimiters out of
+// stored titles.
+func cleanTitle(title string) (string, error) {
+	title = strings.TrimSpace(title)
+	if utf8.RuneCountInString(title) > maxTitleLength || strings.IndexFunc(title, unicode.IsControl) >= 0 {
+		return "", ErrInvalidTitle
+	}
+	return title, nil
+}
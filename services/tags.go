@@ -0,0 +1,36 @@
+package service
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mohan7-code/url-shortener/models"
+)
+
+const maxTags = 10
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// cleanTags lowercases and trims tags and drops duplicates, keeping the
+// first occurrence. An empty list is returned as nil.
+func cleanTags(tags []string) (models.Tags, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	cleaned := make(models.Tags, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !tagPattern.MatchString(tag) {
+			return nil, ErrInvalidTags
+		}
+		if !slices.Contains(cleaned, tag) {
+			cleaned = append(cleaned, tag)
+		}
+	}
+	if len(cleaned) > maxTags {
+		return nil, ErrInvalidTags
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/mohan7-code/url-shortener/dtos"
+	"github.com/mohan7-code/url-shortener/models"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	helper "github.com/mohan7-code/url-shortener/utils/helpers"
+)
+
+const maxTargetingRules = 20
+
+// targetingOS maps the OS values accepted in rules to the families reported
+// by helper.ParseUserAgent.
+var targetingOS = map[string]string{
+	"ios":      "iOS",
+	"android":  "Android",
+	"windows":  "Windows",
+	"macos":    "macOS",
+	"linux":    "Linux",
+	"chromeos": "ChromeOS",
+}
+
+var targetingDevices = []string{helper.DeviceMobile, helper.DeviceTablet, helper.DeviceDesktop}
+
+// prepareTargeting validates a rule set and returns it with its values in
+// canonical case and its destinations normalized and screened like the link's
+// own destination. An empty set is returned as nil.
+func (s *urlServiceImpl) prepareTargeting(ctx *context.Context, rules models.TargetingRules) (models.TargetingRules, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	if len(rules) > maxTargetingRules {
+		return nil, ErrTooManyTargetingRules
+	}
+
+	prepared := make(models.TargetingRules, 0, len(rules))
+	for _, rule := range rules {
+		if len(rule.OS)+len(rule.Device)+len(rule.Language)+len(rule.Country) == 0 {
+			return nil, ErrInvalidTargeting
+		}
+
+		osValues, ok := cleanValues(rule.OS, strings.ToLower, func(v string) bool { return targetingOS[v] != "" })
+		if !ok {
+			return nil, ErrInvalidTargeting
+		}
+		devices, ok := cleanValues(rule.Device, strings.ToLower, func(v string) bool { return slices.Contains(targetingDevices, v) })
+		if !ok {
+			return nil, ErrInvalidTargeting
+		}
+		languages, ok := cleanValues(rule.Language, strings.ToLower, isLanguageTag)
+		if !ok {
+			return nil, ErrInvalidTargeting
+		}
+		countries, ok := cleanValues(rule.Country, strings.ToUpper, isCountryCode)
+		if !ok {
+			return nil, ErrInvalidTargeting
+		}
+
+		if !helper.IsValidURL(rule.Destination) {
+			return nil, ErrInvalidURL
+		}
+		destination, err := normalizeURL(ctx, rule.Destination)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.screenURL(ctx, destination); err != nil {
+			return nil, err
+		}
+
+		prepared = append(prepared, models.TargetingRule{
+			OS:          osValues,
+			Device:      devices,
+			Language:    languages,
+			Country:     countries,
+			Destination: destination,
+		})
+	}
+	return prepared, nil
+}
+
+// chooseDestination replaces the link's OriginalURL with the destination
+// served to this visitor: the first targeting rule they match, otherwise one
+// of the link's variants, otherwise the OriginalURL itself. click.Variant
+// holds the visitor's sticky variant on the way in and the variant served,
+// if any, on the way out.
+func chooseDestination(url *models.URL, click *dtos.ClickInfo) {
+	sticky := ""
+	if click != nil {
+		sticky, click.Variant = click.Variant, ""
+	}
+
+	if destination, ok := matchTargeting(url.Targeting, click); ok {
+		url.OriginalURL = destination
+		return
+	}
+
+	if len(url.Variants) > 0 {
+		variant := pickVariant(url.Variants, sticky)
+		url.OriginalURL = variant.Destination
+		if click != nil {
+			click.Variant = variant.Name
+		}
+	}
+}
+
+// matchTargeting returns the destination of the first rule the visitor
+// matches. Without request metadata no rule matches.
+func matchTargeting(rules models.TargetingRules, click *dtos.ClickInfo) (string, bool) {
+	if len(rules) == 0 || click == nil {
+		return "", false
+	}
+
+	ua := helper.ParseUserAgent(click.UserAgent)
+	language := helper.PreferredLanguage(click.AcceptLanguage)
+	country := strings.ToUpper(click.Country)
+
+	for _, rule := range rules {
+		if len(rule.OS) > 0 && !slices.ContainsFunc(rule.OS, func(v string) bool { return targetingOS[v] == ua.OS }) {
+			continue
+		}
+		if len(rule.Device) > 0 && !slices.Contains(rule.Device, ua.Device) {
+			continue
+		}
+		if len(rule.Language) > 0 && !slices.ContainsFunc(rule.Language, func(v string) bool { return helper.MatchLanguage(v, language) }) {
+			continue
+		}
+		if len(rule.Country) > 0 && !slices.Contains(rule.Country, country) {
+			continue
+		}
+
+		return rule.Destination, true
+	}
+	return "", false
+}
+
+// cleanValues trims and case-folds values and reports whether every one is
+// valid.
+func cleanValues(values []string, fold func(string) string, valid func(string) bool) ([]string, bool) {
+	if len(values) == 0 {
+		return nil, true
+	}
+
+	cleaned := make([]string, len(values))
+	for i, v := range values {
+		cleaned[i] = fold(strings.TrimSpace(v))
+		if !valid(cleaned[i]) {
+			return nil, false
+		}
+	}
+	return cleaned, true
+}
+
+func isCountryCode(code string) bool {
+	return len(code) == 2 && code[0] >= 'A' && code[0] <= 'Z' && code[1] >= 'A' && code[1] <= 'Z'
+}
+
+// isLanguageTag accepts BCP 47 shaped tags such as "en", "pt-br" or
+// "zh-hant-tw": a 2 or 3 letter language followed by alphanumeric subtags.
+func isLanguageTag(tag string) bool {
+	parts := strings.Split(tag, "-")
+	if len(parts[0]) < 2 || len(parts[0]) > 3 {
+		return false
+	}
+	for i, part := range parts {
+		if len(part) == 0 || len(part) > 8 {
+			return false
+		}
+		for _, r := range part {
+			isLetter := r >= 'a' && r <= 'z'
+			if !isLetter && (i == 0 || r < '0' || r > '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
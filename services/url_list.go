@@ -0,0 +1,136 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mohan7-code/url-shortener/dtos"
+	"github.com/mohan7-code/url-shortener/models"
+	"github.com/mohan7-code/url-shortener/repository"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// listSorts maps the sort names accepted by the API to their columns.
+var listSorts = map[string]string{
+	"created_at":    repository.SortCreatedAt,
+	"clicks":        repository.SortClicks,
+	"last_accessed": repository.SortLastAccessed,
+}
+
+// listCursor is the position a page ends at. It is handed out base64
+// encoded and carries the sort it was made for, so it cannot be replayed
+// against another order.
+type listCursor struct {
+	Sort     string    `json:"s"`
+	Desc     bool      `json:"d"`
+	Value    string    `json:"v"`
+	ID       uuid.UUID `json:"i"`
+	Backward bool      `json:"b,omitempty"`
+}
+
+func listFilter(query *dtos.ListQuery) (repository.URLFilter, error) {
+	filter := repository.URLFilter{
+		Domain:      strings.TrimSpace(query.Domain),
+		CreatedFrom: query.CreatedFrom,
+		CreatedTo:   query.CreatedTo,
+		MinClicks:   query.MinClicks,
+		Tag:         strings.ToLower(strings.TrimSpace(query.Tag)),
+		Status:      strings.ToLower(strings.TrimSpace(query.Status)),
+	}
+	if filter.Tag != "" && !tagPattern.MatchString(filter.Tag) {
+		return filter, ErrInvalidTags
+	}
+	if filter.Status != "" && !isValidLinkStatus(filter.Status) {
+		return filter, ErrInvalidStatus
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		return filter, ErrInvalidTimeRange
+	}
+	return filter, nil
+}
+
+func listPage(query *dtos.ListQuery) (repository.KeysetPage, error) {
+	sortName := query.Sort
+	if sortName == "" {
+		sortName = "created_at"
+	}
+	sort, ok := listSorts[sortName]
+	if !ok {
+		return repository.KeysetPage{}, ErrInvalidSort
+	}
+
+	page := repository.KeysetPage{Sort: sort, Desc: true, Limit: defaultListLimit}
+	switch strings.ToLower(query.Order) {
+	case "", "desc":
+	case "asc":
+		page.Desc = false
+	default:
+		return page, ErrInvalidSort
+	}
+	if query.Limit > 0 {
+		page.Limit = min(query.Limit, maxListLimit)
+	}
+
+	if query.Cursor == "" {
+		return page, nil
+	}
+	cursor, err := decodeListCursor(query.Cursor)
+	if err != nil || cursor.Sort != page.Sort || cursor.Desc != page.Desc {
+		return page, ErrInvalidCursor
+	}
+
+	after, err := cursorValue(cursor)
+	if err != nil {
+		return page, ErrInvalidCursor
+	}
+	page.After = after
+	page.AfterID = cursor.ID
+	page.Backward = cursor.Backward
+	return page, nil
+}
+
+func encodeListCursor(page repository.KeysetPage, url *models.URL, backward bool) string {
+	cursor := listCursor{Sort: page.Sort, Desc: page.Desc, ID: url.ID, Backward: backward}
+	switch page.Sort {
+	case repository.SortClicks:
+		cursor.Value = strconv.FormatInt(url.ClickCount, 10)
+	case repository.SortLastAccessed:
+		cursor.Value = url.LastAccessedAt.UTC().Format(time.RFC3339Nano)
+	default:
+		cursor.Value = url.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	raw, _ := json.Marshal(&cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeListCursor(encoded string) (*listCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	var cursor listCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, err
+	}
+	if cursor.ID == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// cursorValue parses the cursor's sort value back into the column's type.
+func cursorValue(cursor *listCursor) (any, error) {
+	if cursor.Sort == repository.SortClicks {
+		return strconv.ParseInt(cursor.Value, 10, 64)
+	}
+	return time.Parse(time.RFC3339Nano, cursor.Value)
+}
@@ -1,15 +1,15 @@
 package service
 
 import (
-	"crypto/sha1"
-	"encoding/base64"
 	"errors"
-	"fmt"
-	"math"
+	"net/http"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/mohan7-code/url-shortener/config"
 	"github.com/mohan7-code/url-shortener/dtos"
 	"github.com/mohan7-code/url-shortener/models"
 	"github.com/mohan7-code/url-shortener/repository"
@@ -21,52 +21,192 @@ import (
 
 type IURLService interface {
 	ShortenURL(ctx *context.Context, req *dtos.URLRequest) (*models.URL, error)
-	GetOriginalURL(ctx *context.Context, shortCode string) (*models.URL, error)
-	ListURLs(ctx *context.Context, page, limit int) (*dtos.ListResponse, error)
-	GetAnalytics(ctx *context.Context, shortCode string) (*dtos.Analytics, error)
+	ShortenBatch(ctx *context.Context, reqs []dtos.URLRequest) ([]ShortenResult, error)
+	GetOriginalURL(ctx *context.Context, host, shortCode string, click *dtos.ClickInfo) (*models.URL, error)
+	UnlockURL(ctx *context.Context, host, shortCode, password string, click *dtos.ClickInfo) (*models.URL, error)
+	ListURLs(ctx *context.Context, query *dtos.ListQuery) (*dtos.CursorListResponse, error)
+	SearchURLs(ctx *context.Context, query *dtos.SearchQuery) ([]*models.SearchHit, error)
+	GetAnalytics(ctx *context.Context, ref dtos.LinkRef, query *dtos.AnalyticsQuery) (*dtos.Analytics, error)
+	UpdateURL(ctx *context.Context, ref dtos.LinkRef, req *dtos.UpdateURLRequest) (*models.URL, error)
+	DeleteURL(ctx *context.Context, ref dtos.LinkRef) error
+	ListClickEvents(ctx *context.Context, ref dtos.LinkRef, from, to time.Time, limit int) ([]*models.ClickEvent, error)
+	GetQRCode(ctx *context.Context, ref dtos.LinkRef, query *dtos.QRQuery) ([]byte, string, error)
 }
 
+const (
+	defaultCacheTTL     = 24 * time.Hour
+	maxClickEventsLimit = 500
+)
+
+// aliasPattern matches the custom aliases that fit the VARCHAR(10) short_code
+// and are safe to put in a URL path.
+var aliasPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,10}$`)
+
 type urlServiceImpl struct {
-	repo repository.IURLRepository
+	repo       repository.IURLRepository
+	clicks     repository.IClickEventRepository
+	workspaces repository.IWorkspaceRepository
+	domains    repository.IDomainRepository
+	codes      CodeGenerator
 }
 
 func NewURLService() IURLService {
+	repo := repository.NewURLRepository()
 	return &urlServiceImpl{
-		repo: repository.NewURLRepository(),
+		repo:       repo,
+		clicks:     repository.NewClickEventRepository(),
+		workspaces: repository.NewWorkspaceRepository(),
+		domains:    repository.NewDomainRepository(),
+		codes:      NewCodeGenerator(repo),
 	}
 }
 
 func (s *urlServiceImpl) ShortenURL(ctx *context.Context, req *dtos.URLRequest) (*models.URL, error) {
 
+	link, err := s.prepareLink(ctx, req, nil)
+	if err != nil {
+		return nil, err
+	}
+	if link.reused {
+		return link.url, nil
+	}
+
+	if err := s.repo.Create(ctx, link.url); err != nil {
+		ctx.Log.Error("failed to create shortened URL", zap.Error(err))
+		return nil, createError(req, err)
+	}
+
+	// set cache eiether way
+	if ttl := cacheTTL(link.url); ttl > 0 {
+		for _, key := range link.cacheKeys() {
+			setCachedLink(ctx, key, link.url, ttl)
+		}
+	}
+
+	ctx.Log.Info("shortened URL created", zap.String("short_code", link.url.ShortCode))
+	return link.url, nil
+}
+
+// pendingLink is a shorten request that passed validation: either a new link
+// with its code assigned but not yet saved, or an existing link to reuse.
+type pendingLink struct {
+	url     *models.URL
+	reused  bool
+	destKey string
+
+	// shared links are cached under destKey so later requests can reuse them
+	shared bool
+}
+
+// cacheKeys returns the keys a newly created link is cached under.
+func (l *pendingLink) cacheKeys() []string {
+	keys := []string{codeCacheKey(l.url.DomainName(), l.url.ShortCode)}
+	if l.shared {
+		keys = append(keys, l.destKey)
+	}
+	return keys
+}
+
+// prepareLink validates a shorten request and resolves it to an existing link
+// or a new one with a free code. Codes in reserved, keyed by codeCacheKey,
+// count as taken; the new link's code is added to it.
+func (s *urlServiceImpl) prepareLink(ctx *context.Context, req *dtos.URLRequest, reserved map[string]bool) (*pendingLink, error) {
+
 	if req.OriginalURL == "" {
-		return nil, errors.New("original URL cannot be empty")
+		return nil, ErrEmptyURL
 	}
 
 	if !helper.IsValidURL(req.OriginalURL) {
 		ctx.Log.Warn("invalid URL format", zap.String("url", req.OriginalURL))
-		return nil, errors.New("invalid URL format — must be a valid  URL")
+		return nil, ErrInvalidURL
 	}
 
-	rdb := cache.New().Client
+	originalURL, err := normalizeURL(ctx, req.OriginalURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.screenURL(ctx, originalURL); err != nil {
+		return nil, err
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidExpiry
+	}
+
+	if req.MaxClicks != nil && *req.MaxClicks <= 0 {
+		return nil, ErrInvalidMaxClicks
+	}
+
+	targeting, err := s.prepareTargeting(ctx, req.Targeting)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := s.prepareVariants(ctx, req.Variants)
+	if err != nil {
+		return nil, err
+	}
+
+	redirectType, err := checkRedirectType(req.RedirectType)
+	if err != nil {
+		return nil, err
+	}
 
-	if cachedShortCode, err := rdb.Get(ctx, req.OriginalURL).Result(); err == nil && cachedShortCode != "" {
-		ctx.Log.Info("cache hit for original URL", zap.String("short_code", cachedShortCode))
-		return &models.URL{
-			OriginalURL: req.OriginalURL,
-			ShortCode:   cachedShortCode,
-		}, nil
+	tags, err := cleanTags(req.Tags)
+	if err != nil {
+		return nil, err
 	}
 
-	// Check if URL already exists in DB
-	existing, err := s.repo.GetByOriginalURL(ctx, req.OriginalURL)
+	title, err := cleanTitle(req.Title)
 	if err != nil {
-		ctx.Log.Error("error checking existing URL", zap.Error(err))
 		return nil, err
 	}
 
-	if existing != nil && existing.ID != uuid.Nil {
-		ctx.Log.Info("url already exists", zap.String("short_code", existing.ShortCode))
-		return existing, nil
+	var passwordHash *string
+	if req.Password != "" {
+		hash, err := hashLinkPassword(req.Password)
+		if err != nil {
+			return nil, err
+		}
+		passwordHash = hash
+	}
+
+	if req.WorkspaceID != nil {
+		if err := authorizeWorkspace(ctx, s.workspaces, *req.WorkspaceID, actionWrite); err != nil {
+			ctx.Log.Warn("not allowed to create links in workspace", zap.String("workspace_id", req.WorkspaceID.String()), zap.Error(err))
+			return nil, err
+		}
+	}
+
+	domain := normalizeHost(req.Domain)
+	if domain != "" {
+		if err := s.checkDomain(ctx, domain, req.WorkspaceID); err != nil {
+			return nil, err
+		}
+	}
+
+	scope := repository.URLScope{OwnerID: ctx.OwnerID}
+	if req.WorkspaceID != nil {
+		scope = repository.URLScope{WorkspaceID: *req.WorkspaceID}
+	}
+	destKey := urlCacheKey(scope, domain, originalURL)
+
+	if reusesExisting(req) {
+		if cached, ok := getCachedLink(ctx, destKey); ok {
+			ctx.Log.Info("cache hit for original URL", zap.String("short_code", cached.ShortCode))
+			return &pendingLink{url: cached.toURL(), reused: true, destKey: destKey}, nil
+		}
+
+		existing, err := s.repo.GetLiveByOriginalURL(ctx, scope, domain, originalURL)
+		if err != nil {
+			ctx.Log.Error("error checking existing URL", zap.Error(err))
+			return nil, err
+		}
+		if existing != nil {
+			ctx.Log.Info("url already exists", zap.String("short_code", existing.ShortCode))
+			return &pendingLink{url: existing, reused: true, destKey: destKey}, nil
+		}
 	}
 
 	var shortCode string
@@ -74,130 +214,310 @@ func (s *urlServiceImpl) ShortenURL(ctx *context.Context, req *dtos.URLRequest)
 	//custom alias, can give your own custom name
 	if req.CustomAlias != "" {
 
-		existingAlias, err := s.repo.GetUrlByShortCode(ctx, req.CustomAlias)
+		if !aliasPattern.MatchString(req.CustomAlias) {
+			return nil, ErrInvalidAlias
+		}
+
+		existingAlias, err := s.repo.GetUrlByShortCode(ctx, domain, req.CustomAlias)
 		if err != nil {
 			ctx.Log.Error("failed to check custom alias availability", zap.Error(err))
 			return nil, err
 		}
 
-		if existingAlias != nil {
+		if existingAlias != nil || reserved[codeCacheKey(domain, req.CustomAlias)] {
 			ctx.Log.Warn("custom alias already taken", zap.String("alias", req.CustomAlias))
-			return nil, errors.New("custom alias already taken, please choose another one")
+			return nil, ErrAliasTaken
 		}
 
 		shortCode = req.CustomAlias
 
 	} else {
 
-		for {
-			shortCode = generateShortCode(req.OriginalURL)
-			existingCode, err := s.repo.GetUrlByShortCode(ctx, shortCode)
-			if err != nil {
-				ctx.Log.Error("failed to check generated short code availability", zap.Error(err))
-				return nil, err
-			}
-			if existingCode == nil {
-				ctx.Log.Info("generated unique short code", zap.String("short_code", shortCode))
-				break
-			}
+		code, err := s.generateShortCode(ctx, domain, reserved)
+		if err != nil {
+			return nil, err
 		}
+		shortCode = code
 	}
 
 	url := &models.URL{
 		ID:             uuid.New(),
 		ShortCode:      shortCode,
-		OriginalURL:    req.OriginalURL,
+		OriginalURL:    originalURL,
 		ClickCount:     0,
 		LastAccessedAt: time.Now(),
+		ExpiresAt:      req.ExpiresAt,
+		MaxClicks:      req.MaxClicks,
+		PasswordHash:   passwordHash,
+		Targeting:      targeting,
+		Variants:       variants,
+		RedirectType:   redirectType,
+		Tags:           tags,
+		Title:          title,
 	}
-
-	err = s.repo.Create(ctx, url)
-	if err != nil {
-		ctx.Log.Error("failed to create shortened URL", zap.Error(err))
-		return nil, err
+	if ctx.IsAuthenticated() {
+		ownerID := ctx.OwnerID
+		url.OwnerID = &ownerID
+	}
+	url.WorkspaceID = req.WorkspaceID
+	if domain != "" {
+		url.Domain = &domain
 	}
 
-	// set cache eiether way
-	rdb.Set(ctx, shortCode, req.OriginalURL, 24*time.Hour).Err()
-	rdb.Set(ctx, req.OriginalURL, shortCode, 24*time.Hour).Err()
+	if reserved != nil {
+		reserved[codeCacheKey(domain, shortCode)] = true
+	}
 
-	ctx.Log.Info("shortened URL created", zap.String("short_code", shortCode))
-	return url, nil
+	return &pendingLink{url: url, destKey: destKey, shared: !isSpecificLink(req)}, nil
 }
 
-func (s *urlServiceImpl) GetOriginalURL(ctx *context.Context, shortCode string) (*models.URL, error) {
+// GetOriginalURL resolves a code on the domain the request was sent to.
+// Hosts that are not registered branded domains use the default domain.
+// Disabled links return ErrLinkDisabled and protected ones
+// ErrPasswordRequired; links under review are returned with their status so
+// the caller can warn before redirecting. The returned OriginalURL is the
+// destination chosen for this visitor by the link's targeting rules and
+// variants.
+func (s *urlServiceImpl) GetOriginalURL(ctx *context.Context, host, shortCode string, click *dtos.ClickInfo) (*models.URL, error) {
 
 	if strings.TrimSpace(shortCode) == "" {
 		return nil, errors.New("short code cannot be empty")
 	}
 
-	rdb := cache.New().Client
-	if cachedURL, err := rdb.Get(ctx, shortCode).Result(); err == nil && cachedURL != "" {
-		ctx.Log.Info("cache hit for short code", zap.String("short_code", shortCode))
+	domain := resolveDomain(ctx, s.domains, host)
 
-		if err := s.repo.IncrementClickCountByShortCode(ctx, shortCode); err != nil {
-			ctx.Log.Warn("failed to increment click count ", zap.String("short_code", shortCode), zap.Error(err))
+	if cached, ok := getCachedLink(ctx, codeCacheKey(domain, shortCode)); ok {
+		ctx.Log.Info("cache hit for short code", zap.String("short_code", shortCode), zap.String("domain", domain))
+
+		url := cached.toURL()
+		chooseDestination(url, click)
+		if url.Status == models.StatusActive {
+			s.recordClick(ctx, url.ID, click)
 		}
-		return &models.URL{OriginalURL: cachedURL}, nil
+		return url, nil
 	}
 
-	url, err := s.repo.GetUrlByShortCode(ctx, shortCode)
+	url, err := s.loadLink(ctx, domain, shortCode)
 	if err != nil {
-		ctx.Log.Error("failed to fetch original URL", zap.Error(err))
 		return nil, err
 	}
-	if url == nil {
-		return nil, errors.New("short code not found")
+
+	// protected links are never cached, so every visit goes through UnlockURL
+	if url.IsProtected() {
+		return nil, ErrPasswordRequired
 	}
 
 	// Cache for future requests
-	rdb.Set(ctx, shortCode, url.OriginalURL, 24*time.Hour)
+	if ttl := cacheTTL(url); ttl > 0 {
+		setCachedLink(ctx, codeCacheKey(domain, shortCode), url, ttl)
+	}
 
-	if err := s.repo.IncrementClickCount(ctx, url.ID.String()); err != nil {
-		ctx.Log.Warn("failed to increment click count", zap.String("short_code", shortCode))
+	chooseDestination(url, click)
+
+	// visits to a link under review only see the warning page
+	if url.Status == models.StatusActive {
+		if err := s.countClick(ctx, url, click); err != nil {
+			return nil, err
+		}
 	}
 
 	return url, nil
 }
 
-func (s *urlServiceImpl) ListURLs(ctx *context.Context, page, limit int) (*dtos.ListResponse, error) {
+// loadLink reads a code from the database and checks it may still be
+// visited. Disabled links are never cached, so restoring one takes effect
+// at once.
+func (s *urlServiceImpl) loadLink(ctx *context.Context, domain, shortCode string) (*models.URL, error) {
+	url, err := s.repo.GetUrlByShortCode(ctx, domain, shortCode)
+	if err != nil {
+		ctx.Log.Error("failed to fetch original URL", zap.Error(err))
+		return nil, err
+	}
+	if url == nil {
+		return nil, ErrShortCodeNotFound
+	}
+
+	if url.IsExpired(time.Now()) || url.IsExhausted() {
+		ctx.Log.Info("link is no longer available", zap.String("short_code", shortCode))
+		return nil, ErrLinkGone
+	}
 
-	if page <= 0 {
-		page = 1
+	if url.Status == models.StatusDisabled {
+		ctx.Log.Info("link is disabled", zap.String("short_code", shortCode))
+		return nil, ErrLinkDisabled
 	}
+	return url, nil
+}
 
-	offset := (page - 1) * limit
-	if limit == 0 {
-		offset = 0
+// countClick records a visit to a loaded link. Click-limited links claim
+// their click synchronously and return ErrLinkGone once the limit is
+// reached, even if the row read by loadLink still had clicks left.
+func (s *urlServiceImpl) countClick(ctx *context.Context, url *models.URL, click *dtos.ClickInfo) error {
+	if url.MaxClicks == nil {
+		s.recordClick(ctx, url.ID, click)
+		return nil
+	}
+	if click != nil && click.Uncounted {
+		return nil
 	}
 
-	urls, total, err := s.repo.ListURLs(ctx, limit, offset)
+	claimed, err := s.clicks.ClaimClick(ctx, newClickEvent(url.ID, click))
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		ctx.Log.Info("link reached its click limit", zap.String("short_code", url.ShortCode))
+		return ErrLinkGone
+	}
+	return nil
+}
+
+// recordClick queues a click event for the link, or writes it straight away
+// when no buffer is running. Uncounted requests are skipped. Failures are
+// logged and never block the redirect.
+func (s *urlServiceImpl) recordClick(ctx *context.Context, urlID uuid.UUID, click *dtos.ClickInfo) {
+	if click != nil && click.Uncounted {
+		return
+	}
+
+	event := newClickEvent(urlID, click)
+	if buffer := getClickBuffer(); buffer != nil && buffer.Enqueue(event) {
+		return
+	}
+
+	if err := s.clicks.RecordClick(ctx, event); err != nil {
+		ctx.Log.Warn("failed to record click", zap.String("url_id", urlID.String()), zap.Error(err))
+	}
+}
+
+func newClickEvent(urlID uuid.UUID, click *dtos.ClickInfo) *models.ClickEvent {
+	event := &models.ClickEvent{
+		URLID:     urlID,
+		ClickedAt: time.Now(),
+	}
+	if click != nil {
+		ua := helper.ParseUserAgent(click.UserAgent)
+
+		event.Referrer = click.Referrer
+		event.ReferrerHost = helper.HostOf(click.Referrer)
+		event.UserAgent = click.UserAgent
+		event.Browser = ua.Browser
+		event.OS = ua.OS
+		event.Device = ua.Device
+		event.AcceptLanguage = click.AcceptLanguage
+		if len(click.Country) == 2 {
+			event.Country = strings.ToUpper(click.Country)
+		}
+		event.Variant = click.Variant
+		if click.ClientIP != "" {
+			event.IPHash = helper.HashIP(click.ClientIP, config.AppConfig.IPHashSalt)
+		}
+	}
+	return event
+}
+
+// ListURLs returns one page of the caller's links, or a workspace's, newest
+// first unless another sort is asked for. Pages are found by keyset on the
+// sort column and id, so they stay stable while links are being added.
+func (s *urlServiceImpl) ListURLs(ctx *context.Context, query *dtos.ListQuery) (*dtos.CursorListResponse, error) {
+
+	if !ctx.IsAuthenticated() {
+		return nil, ErrUnauthorized
+	}
+
+	scope := repository.URLScope{OwnerID: ctx.OwnerID}
+	if query.WorkspaceID != uuid.Nil {
+		if err := authorizeWorkspace(ctx, s.workspaces, query.WorkspaceID, actionRead); err != nil {
+			return nil, err
+		}
+		scope = repository.URLScope{WorkspaceID: query.WorkspaceID}
+	}
+
+	filter, err := listFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := listPage(query)
+	if err != nil {
+		return nil, err
+	}
+	limit := page.Limit
+	page.Limit++ // one extra row tells whether another page follows
+
+	urls, err := s.repo.ListURLs(ctx, scope, filter, page)
 	if err != nil {
 		ctx.Log.Error("failed to list URLs", zap.Error(err))
 		return nil, err
 	}
 
-	totalPages := 1
-	if limit > 0 {
-		totalPages = int(math.Ceil(float64(total) / float64(limit)))
+	more := len(urls) > limit
+	if more {
+		urls = urls[:limit]
+	}
+	if page.Backward {
+		slices.Reverse(urls)
+	}
+
+	resp := &dtos.CursorListResponse{Data: urls}
+	if len(urls) == 0 {
+		resp.Data = []*models.URL{}
+		return resp, nil
 	}
 
-	return &dtos.ListResponse{
-		Data:       urls,
-		TotalCount: total,
-		Pages:      totalPages,
-	}, nil
+	first, last := urls[0], urls[len(urls)-1]
+	if page.Backward {
+		if more {
+			resp.PrevCursor = encodeListCursor(page, first, true)
+		}
+		resp.NextCursor = encodeListCursor(page, last, false)
+	} else {
+		if more {
+			resp.NextCursor = encodeListCursor(page, last, false)
+		}
+		if query.Cursor != "" {
+			resp.PrevCursor = encodeListCursor(page, first, true)
+		}
+	}
+	return resp, nil
 }
 
-func (s *urlServiceImpl) GetAnalytics(ctx *context.Context, shortCode string) (*dtos.Analytics, error) {
+func (s *urlServiceImpl) GetAnalytics(ctx *context.Context, ref dtos.LinkRef, query *dtos.AnalyticsQuery) (*dtos.Analytics, error) {
+
+	interval := query.Interval
+	if interval == "" {
+		interval = IntervalDay
+	}
+	if !isValidInterval(interval) {
+		return nil, ErrInvalidInterval
+	}
 
-	url, err := s.repo.GetUrlByShortCode(ctx, shortCode)
+	to := query.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+	from := query.From
+	if from.IsZero() {
+		from = to.Add(-defaultAnalyticsRange)
+	}
+	if !from.Before(to) {
+		return nil, ErrInvalidTimeRange
+	}
+	if countBuckets(from, to, interval, maxAnalyticsBuckets) > maxAnalyticsBuckets {
+		return nil, ErrTooManyBuckets
+	}
+
+	url, err := s.getAuthorizedURL(ctx, ref, actionRead)
 	if err != nil {
-		ctx.Log.Error("failed to fetch analytics", zap.Error(err))
 		return nil, err
 	}
-	if url == nil {
-		return nil, errors.New("short code not found")
+
+	urlID := url.ID.String()
+
+	counts, err := s.clicks.ClickTimeSeries(ctx, urlID, from, to, interval)
+	if err != nil {
+		ctx.Log.Error("failed to fetch click time series", zap.Error(err))
+		return nil, err
 	}
 
 	result := &dtos.Analytics{
@@ -205,13 +525,384 @@ func (s *urlServiceImpl) GetAnalytics(ctx *context.Context, shortCode string) (*
 		OriginalURL:    url.OriginalURL,
 		ClickCount:     url.ClickCount,
 		LastAccessedAt: url.LastAccessedAt,
+		From:           from,
+		To:             to,
+		Interval:       interval,
+		TimeSeries:     fillTimeSeries(counts, from, to, interval),
+	}
+	for _, bucket := range counts {
+		result.RangeClicks += bucket.Clicks
+	}
+
+	breakdowns := []struct {
+		dimension string
+		target    *[]dtos.BreakdownItem
+	}{
+		{repository.DimensionReferrer, &result.TopReferrers},
+		{repository.DimensionBrowser, &result.Browsers},
+		{repository.DimensionOS, &result.OperatingSystems},
+		{repository.DimensionCountry, &result.Countries},
+		{repository.DimensionVariant, &result.Variants},
+	}
+	for _, b := range breakdowns {
+		items, err := s.clicks.ClickBreakdown(ctx, urlID, b.dimension, from, to, topBreakdownItems)
+		if err != nil {
+			ctx.Log.Error("failed to fetch click breakdown", zap.String("dimension", b.dimension), zap.Error(err))
+			return nil, err
+		}
+		if items == nil {
+			items = []dtos.BreakdownItem{}
+		}
+		*b.target = items
 	}
 
 	return result, nil
 }
 
-func generateShortCode(url string) string {
-	hash := sha1.New()
-	hash.Write([]byte(fmt.Sprintf("%s-%d", url, time.Now().UnixNano())))
-	return base64.URLEncoding.EncodeToString(hash.Sum(nil))[:8]
+func (s *urlServiceImpl) UpdateURL(ctx *context.Context, ref dtos.LinkRef, req *dtos.UpdateURLRequest) (*models.URL, error) {
+
+	if req.OriginalURL == "" && req.Targeting == nil && req.Variants == nil && req.RedirectType == nil && req.Tags == nil && req.Title == nil {
+		return nil, ErrNothingToUpdate
+	}
+
+	originalURL := ""
+	if req.OriginalURL != "" {
+		if !helper.IsValidURL(req.OriginalURL) {
+			ctx.Log.Warn("invalid URL format", zap.String("url", req.OriginalURL))
+			return nil, ErrInvalidURL
+		}
+
+		normalized, err := normalizeURL(ctx, req.OriginalURL)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.screenURL(ctx, normalized); err != nil {
+			return nil, err
+		}
+		originalURL = normalized
+	}
+
+	var targeting models.TargetingRules
+	if req.Targeting != nil {
+		prepared, err := s.prepareTargeting(ctx, *req.Targeting)
+		if err != nil {
+			return nil, err
+		}
+		targeting = prepared
+	}
+
+	var variants models.Variants
+	if req.Variants != nil {
+		prepared, err := s.prepareVariants(ctx, *req.Variants)
+		if err != nil {
+			return nil, err
+		}
+		variants = prepared
+	}
+
+	redirectType := 0
+	if req.RedirectType != nil {
+		checked, err := checkRedirectType(*req.RedirectType)
+		if err != nil {
+			return nil, err
+		}
+		redirectType = checked
+	}
+
+	var tags models.Tags
+	if req.Tags != nil {
+		cleaned, err := cleanTags(*req.Tags)
+		if err != nil {
+			return nil, err
+		}
+		tags = cleaned
+	}
+
+	title := ""
+	if req.Title != nil {
+		cleaned, err := cleanTitle(*req.Title)
+		if err != nil {
+			return nil, err
+		}
+		title = cleaned
+	}
+
+	url, err := s.getAuthorizedURL(ctx, ref, actionWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	updateURL := originalURL != "" && url.OriginalURL != originalURL
+	updateRedirect := redirectType != 0 && url.RedirectType != redirectType
+	updateTitle := req.Title != nil && url.Title != title
+	if !updateURL && !updateRedirect && !updateTitle && req.Targeting == nil && req.Variants == nil && req.Tags == nil {
+		return url, nil
+	}
+
+	if updateURL {
+		if err := s.repo.UpdateOriginalURL(ctx, url.ID.String(), originalURL); err != nil {
+			ctx.Log.Error("failed to update URL", zap.Error(err))
+			return nil, err
+		}
+	}
+	if req.Targeting != nil {
+		if err := s.repo.UpdateTargeting(ctx, url.ID.String(), targeting); err != nil {
+			ctx.Log.Error("failed to update targeting", zap.Error(err))
+			return nil, err
+		}
+	}
+	if req.Variants != nil {
+		if err := s.repo.UpdateVariants(ctx, url.ID.String(), variants); err != nil {
+			ctx.Log.Error("failed to update variants", zap.Error(err))
+			return nil, err
+		}
+	}
+	if updateRedirect {
+		if err := s.repo.UpdateRedirectType(ctx, url.ID.String(), redirectType); err != nil {
+			ctx.Log.Error("failed to update redirect type", zap.Error(err))
+			return nil, err
+		}
+	}
+	if req.Tags != nil {
+		if err := s.repo.UpdateTags(ctx, url.ID.String(), tags); err != nil {
+			ctx.Log.Error("failed to update tags", zap.Error(err))
+			return nil, err
+		}
+	}
+	if updateTitle {
+		if err := s.repo.UpdateTitle(ctx, url.ID.String(), title); err != nil {
+			ctx.Log.Error("failed to update title", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	// drop both directions so redirects never serve the old destination
+	invalidateCache(ctx, url)
+
+	if updateURL {
+		url.OriginalURL = originalURL
+	}
+	if req.Targeting != nil {
+		url.Targeting = targeting
+	}
+	if req.Variants != nil {
+		url.Variants = variants
+	}
+	if updateRedirect {
+		url.RedirectType = redirectType
+	}
+	if req.Tags != nil {
+		url.Tags = tags
+	}
+	if updateTitle {
+		url.Title = title
+	}
+
+	ctx.Log.Info("shortened URL updated", zap.String("short_code", url.ShortCode))
+	return url, nil
+}
+
+func (s *urlServiceImpl) DeleteURL(ctx *context.Context, ref dtos.LinkRef) error {
+
+	url, err := s.getAuthorizedURL(ctx, ref, actionWrite)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteByID(ctx, url.ID.String()); err != nil {
+		ctx.Log.Error("failed to delete URL", zap.Error(err))
+		return err
+	}
+
+	invalidateCache(ctx, url)
+
+	ctx.Log.Info("shortened URL deleted", zap.String("short_code", url.ShortCode))
+	return nil
+}
+
+func (s *urlServiceImpl) ListClickEvents(ctx *context.Context, ref dtos.LinkRef, from, to time.Time, limit int) ([]*models.ClickEvent, error) {
+
+	if limit <= 0 || limit > maxClickEventsLimit {
+		limit = maxClickEventsLimit
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if !from.Before(to) {
+		return nil, ErrInvalidTimeRange
+	}
+
+	url, err := s.getAuthorizedURL(ctx, ref, actionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.clicks.ListByURL(ctx, url.ID.String(), from, to, limit)
+	if err != nil {
+		ctx.Log.Error("failed to list click events", zap.Error(err))
+		return nil, err
+	}
+	return events, nil
+}
+
+// getAuthorizedURL loads a link the caller may perform act on. Links the
+// caller cannot see are reported as not found so their codes are not disclosed.
+// Disabled and under review links are read-only until an admin restores
+// them, so their owners cannot change the destination or delete the link to
+// free the alias.
+func (s *urlServiceImpl) getAuthorizedURL(ctx *context.Context, ref dtos.LinkRef, act action) (*models.URL, error) {
+
+	if !ctx.IsAuthenticated() {
+		return nil, ErrUnauthorized
+	}
+
+	url, err := s.repo.GetUrlByShortCode(ctx, normalizeHost(ref.Domain), ref.ShortCode)
+	if err != nil {
+		ctx.Log.Error("failed to fetch url", zap.String("short_code", ref.ShortCode), zap.Error(err))
+		return nil, err
+	}
+	if url == nil {
+		return nil, ErrShortCodeNotFound
+	}
+
+	if err := authorizeLink(ctx, s.workspaces, url, act); err != nil {
+		return nil, err
+	}
+	if act == actionWrite && url.Status != models.StatusActive {
+		return nil, ErrLinkModerated
+	}
+	return url, nil
+}
+
+// checkDomain verifies a branded domain is registered to the workspace the
+// link is created in. Personal links always use the default domain.
+func (s *urlServiceImpl) checkDomain(ctx *context.Context, domain string, workspaceID *uuid.UUID) error {
+	if workspaceID == nil {
+		return ErrDomainNotAllowed
+	}
+
+	registered, err := s.domains.GetByHostname(ctx, domain)
+	if err != nil {
+		return err
+	}
+	if registered == nil || registered.WorkspaceID != *workspaceID {
+		return ErrDomainNotAllowed
+	}
+	return nil
+}
+
+// invalidateCache removes the code->URL and URL->code keys written by ShortenURL.
+func invalidateCache(ctx *context.Context, url *models.URL) {
+	rdb := cache.New().Client
+	destKey := urlCacheKey(scopeOf(url), url.DomainName(), url.OriginalURL)
+	if err := rdb.Del(ctx, codeCacheKey(url.DomainName(), url.ShortCode), destKey).Err(); err != nil {
+		ctx.Log.Warn("failed to invalidate cache", zap.String("short_code", url.ShortCode), zap.Error(err))
+	}
+}
+
+// normalizeURL canonicalizes a destination with the steps enabled in the
+// config, so equivalent URLs are stored and deduplicated as one.
+func normalizeURL(ctx *context.Context, rawURL string) (string, error) {
+	cfg := config.AppConfig
+	normalized, err := helper.NormalizeURL(rawURL, helper.NormalizeOptions{
+		LowercaseHost:     cfg.NormalizeLowercaseHost,
+		RemoveDefaultPort: cfg.NormalizeDefaultPort,
+		Punycode:          cfg.NormalizePunycode,
+		CleanPath:         cfg.NormalizePath,
+		SortQuery:         cfg.NormalizeSortQuery,
+		StripTracking:     cfg.NormalizeStripTracking,
+		TrackingParams:    cfg.TrackingParams,
+	})
+	if err != nil {
+		ctx.Log.Warn("failed to normalize URL", zap.String("url", rawURL), zap.Error(err))
+		return "", ErrInvalidURL
+	}
+	return normalized, nil
+}
+
+// reusesExisting reports whether a shorten request may be answered with an
+// existing link. Specific links are always created anew.
+func reusesExisting(req *dtos.URLRequest) bool {
+	if isSpecificLink(req) {
+		return false
+	}
+	return req.ReuseExisting == nil || *req.ReuseExisting
+}
+
+// isSpecificLink reports whether a shorten request asks for an alias, expiry,
+// click limit, password, targeting rules, variants, tags, title or a redirect type
+// other than 302. Such links are never shared with plain requests for the
+// same destination.
+func isSpecificLink(req *dtos.URLRequest) bool {
+	return req.CustomAlias != "" || req.ExpiresAt != nil || req.MaxClicks != nil || req.Password != "" ||
+		len(req.Targeting) > 0 || len(req.Variants) > 0 || len(req.Tags) > 0 || strings.TrimSpace(req.Title) != "" ||
+		(req.RedirectType != 0 && req.RedirectType != http.StatusFound)
+}
+
+// checkRedirectType returns the redirect status a link is created with. Zero
+// selects the default 302.
+func checkRedirectType(redirectType int) (int, error) {
+	switch redirectType {
+	case 0:
+		return http.StatusFound, nil
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return redirectType, nil
+	}
+	return 0, ErrInvalidRedirectType
+}
+
+// scopeOf returns the scope a link was deduplicated in when it was created.
+func scopeOf(url *models.URL) repository.URLScope {
+	if url.WorkspaceID != nil {
+		return repository.URLScope{WorkspaceID: *url.WorkspaceID}
+	}
+	if url.OwnerID != nil {
+		return repository.URLScope{OwnerID: *url.OwnerID}
+	}
+	return repository.URLScope{}
+}
+
+// cacheTTL returns how long a link may live in Redis. Links with a click
+// limit are never cached so every redirect is checked against the database,
+// protected links so a cache hit can never skip the password, and links with
+// an expiry are cached no longer than they are valid.
+func cacheTTL(url *models.URL) time.Duration {
+	if url.MaxClicks != nil || url.IsProtected() {
+		return 0
+	}
+
+	ttl := defaultCacheTTL
+	if url.ExpiresAt != nil {
+		if untilExpiry := time.Until(*url.ExpiresAt); untilExpiry < ttl {
+			ttl = untilExpiry
+		}
+	}
+	return ttl
+}
+
+// generateShortCode asks the configured generator for a code that is free
+// on the domain and not in reserved. Generated codes can still clash with
+// custom aliases, so a few attempts are made before giving up.
+func (s *urlServiceImpl) generateShortCode(ctx *context.Context, domain string, reserved map[string]bool) (string, error) {
+	for range maxCodeAttempts {
+		shortCode, err := s.codes.Generate(ctx)
+		if err != nil {
+			ctx.Log.Error("failed to generate short code", zap.Error(err))
+			return "", err
+		}
+
+		existing, err := s.repo.GetUrlByShortCode(ctx, domain, shortCode)
+		if err != nil {
+			ctx.Log.Error("failed to check generated short code availability", zap.Error(err))
+			return "", err
+		}
+		if existing == nil && !reserved[codeCacheKey(domain, shortCode)] {
+			ctx.Log.Info("generated unique short code", zap.String("short_code", shortCode))
+			return shortCode, nil
+		}
+	}
+
+	ctx.Log.Error("no free short code found", zap.Int("attempts", maxCodeAttempts))
+	return "", ErrCodeSpaceExhausted
 }
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"math/rand/v2"
+	"regexp"
+	"strings"
+
+	"github.com/mohan7-code/url-shortener/models"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	helper "github.com/mohan7-code/url-shortener/utils/helpers"
+)
+
+const (
+	minVariants      = 2
+	maxVariants      = 10
+	maxVariantWeight = 1000
+)
+
+var variantNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// prepareVariants validates an A/B split and returns it with lowercased
+// names and normalized, screened destinations. Unnamed variants are called
+// "a", "b", ... by position. An empty split is returned as nil.
+func (s *urlServiceImpl) prepareVariants(ctx *context.Context, variants models.Variants) (models.Variants, error) {
+	if len(variants) == 0 {
+		return nil, nil
+	}
+	if len(variants) < minVariants || len(variants) > maxVariants {
+		return nil, ErrInvalidVariants
+	}
+
+	prepared := make(models.Variants, 0, len(variants))
+	seen := make(map[string]bool, len(variants))
+	for i, variant := range variants {
+		name := strings.ToLower(strings.TrimSpace(variant.Name))
+		if name == "" {
+			name = string(rune('a' + i))
+		}
+		if !variantNamePattern.MatchString(name) || seen[name] {
+			return nil, ErrInvalidVariants
+		}
+		seen[name] = true
+
+		if variant.Weight < 1 || variant.Weight > maxVariantWeight {
+			return nil, ErrInvalidVariants
+		}
+
+		if !helper.IsValidURL(variant.Destination) {
+			return nil, ErrInvalidURL
+		}
+		destination, err := normalizeURL(ctx, variant.Destination)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.screenURL(ctx, destination); err != nil {
+			return nil, err
+		}
+
+		prepared = append(prepared, models.Variant{
+			Name:        name,
+			Destination: destination,
+			Weight:      variant.Weight,
+		})
+	}
+	return prepared, nil
+}
+
+// pickVariant returns the sticky variant when it is still part of the split,
+// and otherwise draws one with probability proportional to its weight.
+func pickVariant(variants models.Variants, sticky string) models.Variant {
+	if variant, ok := variants.Find(sticky); ok && sticky != "" {
+		return variant
+	}
+
+	total := 0
+	for _, variant := range variants {
+		total += variant.Weight
+	}
+
+	n := rand.IntN(total)
+	for _, variant := range variants {
+		if n < variant.Weight {
+			return variant
+		}
+		n -= variant.Weight
+	}
+	return variants[len(variants)-1]
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mohan7-code/url-shortener/dtos"
+	"github.com/mohan7-code/url-shortener/models"
+	"github.com/mohan7-code/url-shortener/repository"
+	context "github.com/mohan7-code/url-shortener/utils/context"
+	"go.uber.org/zap"
+)
+
+type IWorkspaceService interface {
+	CreateWorkspace(ctx *context.Context, req *dtos.WorkspaceRequest) (*models.Workspace, error)
+	ListWorkspaces(ctx *context.Context) ([]*models.WorkspaceWithRole, error)
+	ListMembers(ctx *context.Context, workspaceID uuid.UUID) ([]*models.WorkspaceMember, error)
+	AddMember(ctx *context.Context, workspaceID uuid.UUID, req *dtos.WorkspaceMemberRequest) (*models.WorkspaceMember, error)
+	UpdateMemberRole(ctx *context.Context, workspaceID, memberID uuid.UUID, role string) (*models.WorkspaceMember, error)
+	RemoveMember(ctx *context.Context, workspaceID, memberID uuid.UUID) error
+}
+
+type workspaceServiceImpl struct {
+	repo repository.IWorkspaceRepository
+}
+
+func NewWorkspaceService() IWorkspaceService {
+	return &workspaceServiceImpl{
+		repo: repository.NewWorkspaceRepository(),
+	}
+}
+
+// CreateWorkspace creates a workspace with the caller as its first admin.
+func (s *workspaceServiceImpl) CreateWorkspace(ctx *context.Context, req *dtos.WorkspaceRequest) (*models.Workspace, error) {
+
+	if !ctx.IsAuthenticated() {
+		return nil, ErrUnauthorized
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, ErrInvalidWorkspace
+	}
+
+	workspace := &models.Workspace{
+		ID:        uuid.New(),
+		Name:      name,
+		CreatedBy: ctx.OwnerID,
+	}
+	admin := &models.WorkspaceMember{
+		WorkspaceID: workspace.ID,
+		MemberID:    ctx.OwnerID,
+		Role:        models.RoleAdmin,
+	}
+
+	if err := s.repo.Create(ctx, workspace, admin); err != nil {
+		ctx.Log.Error("failed to create workspace", zap.Error(err))
+		return nil, err
+	}
+
+	ctx.Log.Info("workspace created", zap.String("workspace_id", workspace.ID.String()))
+	return workspace, nil
+}
+
+func (s *workspaceServiceImpl) ListWorkspaces(ctx *context.Context) ([]*models.WorkspaceWithRole, error) {
+
+	if !ctx.IsAuthenticated() {
+		return nil, ErrUnauthorized
+	}
+
+	workspaces, err := s.repo.ListForMember(ctx, ctx.OwnerID.String())
+	if err != nil {
+		ctx.Log.Error("failed to list workspaces", zap.Error(err))
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+func (s *workspaceServiceImpl) ListMembers(ctx *context.Context, workspaceID uuid.UUID) ([]*models.WorkspaceMember, error) {
+
+	if err := authorizeWorkspace(ctx, s.repo, workspaceID, actionRead); err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.ListMembers(ctx, workspaceID.String())
+	if err != nil {
+		ctx.Log.Error("failed to list workspace members", zap.Error(err))
+		return nil, err
+	}
+	return members, nil
+}
+
+func (s *workspaceServiceImpl) AddMember(ctx *context.Context, workspaceID uuid.UUID, req *dtos.WorkspaceMemberRequest) (*models.WorkspaceMember, error) {
+
+	if err := authorizeWorkspace(ctx, s.repo, workspaceID, actionManage); err != nil {
+		return nil, err
+	}
+
+	if req.MemberID == uuid.Nil {
+		return nil, ErrMemberNotFound
+	}
+	if !isValidRole(req.Role) {
+		return nil, ErrInvalidRole
+	}
+
+	existing, err := s.repo.GetMember(ctx, workspaceID.String(), req.MemberID.String())
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrMemberExists
+	}
+
+	member := &models.WorkspaceMember{
+		WorkspaceID: workspaceID,
+		MemberID:    req.MemberID,
+		Role:        req.Role,
+	}
+	if err := s.repo.AddMember(ctx, member); err != nil {
+		ctx.Log.Error("failed to add workspace member", zap.Error(err))
+		return nil, err
+	}
+
+	ctx.Log.Info("workspace member added", zap.String("workspace_id", workspaceID.String()), zap.String("member_id", req.MemberID.String()))
+	return member, nil
+}
+
+func (s *workspaceServiceImpl) UpdateMemberRole(ctx *context.Context, workspaceID, memberID uuid.UUID, role string) (*models.WorkspaceMember, error) {
+
+	if err := authorizeWorkspace(ctx, s.repo, workspaceID, actionManage); err != nil {
+		return nil, err
+	}
+
+	if !isValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+
+	member, err := s.repo.GetMember(ctx, workspaceID.String(), memberID.String())
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, ErrMemberNotFound
+	}
+
+	if member.Role == models.RoleAdmin && role != models.RoleAdmin {
+		if err := s.ensureAnotherAdmin(ctx, workspaceID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.UpdateMemberRole(ctx, workspaceID.String(), memberID.String(), role); err != nil {
+		ctx.Log.Error("failed to update workspace member", zap.Error(err))
+		return nil, err
+	}
+
+	member.Role = role
+	return member, nil
+}
+
+func (s *workspaceServiceImpl) RemoveMember(ctx *context.Context, workspaceID, memberID uuid.UUID) error {
+
+	if err := authorizeWorkspace(ctx, s.repo, workspaceID, actionManage); err != nil {
+		return err
+	}
+
+	member, err := s.repo.GetMember(ctx, workspaceID.String(), memberID.String())
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return ErrMemberNotFound
+	}
+
+	if member.Role == models.RoleAdmin {
+		if err := s.ensureAnotherAdmin(ctx, workspaceID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.RemoveMember(ctx, workspaceID.String(), memberID.String()); err != nil {
+		ctx.Log.Error("failed to remove workspace member", zap.Error(err))
+		return err
+	}
+
+	ctx.Log.Info("workspace member removed", zap.String("workspace_id", workspaceID.String()), zap.String("member_id", memberID.String()))
+	return nil
+}
+
+func (s *workspaceServiceImpl) ensureAnotherAdmin(ctx *context.Context, workspaceID uuid.UUID) error {
+	admins, err := s.repo.CountAdmins(ctx, workspaceID.String())
+	if err != nil {
+		return err
+	}
+	if admins <= 1 {
+		return ErrLastAdmin
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package context
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/mohan7-code/url-shortener/database"
 	"go.uber.org/zap"
 )
@@ -9,14 +10,41 @@ import (
 type Context struct {
 	DB  *database.DBConn
 	Log *zap.Logger
+
+	// OwnerID, APIKeyID, Plan and IsAdmin are set by the middleware when
+	// the request carries a valid API key, and are zero otherwise.
+	OwnerID  uuid.UUID
+	APIKeyID uuid.UUID
+	Plan     string
+	IsAdmin  bool
+
 	*gin.Context
 }
 
 func (a *Context) Copy() *Context {
 
 	return &Context{
-		DB:      a.DB,
-		Log:     a.Log,
-		Context: a.Context.Copy(),
+		DB:       a.DB,
+		Log:      a.Log,
+		OwnerID:  a.OwnerID,
+		APIKeyID: a.APIKeyID,
+		Plan:     a.Plan,
+		IsAdmin:  a.IsAdmin,
+		Context:  a.Context.Copy(),
+	}
+}
+
+// IsAuthenticated reports whether the request was made with a valid API key.
+func (a *Context) IsAuthenticated() bool {
+	return a.OwnerID != uuid.Nil
+}
+
+// NewBackground builds a Context for work that runs outside of an HTTP
+// request, such as the background workers started from main.
+func NewBackground(log *zap.Logger) *Context {
+	return &Context{
+		DB:      database.New(),
+		Log:     log,
+		Context: &gin.Context{},
 	}
 }
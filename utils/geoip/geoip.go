@@ -0,0 +1,463 @@
+// Package geoip reads MaxMind DB (MMDB) files, the format of GeoLite2 and
+// GeoIP2 databases, and looks up the country of an IP address. It has no
+// dependencies and works entirely from the local file.
+package geoip
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+	"os"
+)
+
+var (
+	ErrInvalidDatabase = errors.New("invalid MaxMind DB file")
+
+	metadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+)
+
+const (
+	// the metadata is stored in the last 128KiB of the file
+	maxMetadataSize = 128 * 1024
+	// 16 zero bytes separate the search tree from the data section
+	dataSectionSeparator = 16
+)
+
+// Data section field types.
+const (
+	typeExtended  = 0
+	typePointer   = 1
+	typeString    = 2
+	typeDouble    = 3
+	typeBytes     = 4
+	typeUint16    = 5
+	typeUint32    = 6
+	typeMap       = 7
+	typeInt32     = 8
+	typeUint64    = 9
+	typeUint128   = 10
+	typeArray     = 11
+	typeContainer = 12
+	typeEndMarker = 13
+	typeBool      = 14
+	typeFloat     = 15
+)
+
+// Reader is an opened database. It is safe for concurrent use.
+type Reader struct {
+	buf        []byte
+	data       []byte
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	ipv4Start  uint
+
+	// DatabaseType is the type recorded in the metadata, e.g. "GeoLite2-Country".
+	DatabaseType string
+}
+
+// Open reads the database file into memory.
+func Open(path string) (*Reader, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return FromBytes(buf)
+}
+
+// FromBytes parses a database held in memory.
+func FromBytes(buf []byte) (*Reader, error) {
+	tail := buf
+	if len(tail) > maxMetadataSize {
+		tail = tail[len(tail)-maxMetadataSize:]
+	}
+	i := bytes.LastIndex(tail, metadataMarker)
+	if i < 0 {
+		return nil, ErrInvalidDatabase
+	}
+	metaStart := len(buf) - len(tail) + i + len(metadataMarker)
+
+	meta := decoder{buf: buf[metaStart:]}
+	value, _, err := meta.decode(0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%w: metadata: %v", ErrInvalidDatabase, err)
+	}
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return nil, ErrInvalidDatabase
+	}
+
+	r := &Reader{buf: buf}
+	r.nodeCount = metadataUint(fields, "node_count")
+	r.recordSize = metadataUint(fields, "record_size")
+	r.ipVersion = metadataUint(fields, "ip_version")
+	r.DatabaseType, _ = fields["database_type"].(string)
+
+	switch r.recordSize {
+	case 24, 28, 32:
+	default:
+		return nil, fmt.Errorf("%w: unsupported record size %d", ErrInvalidDatabase, r.recordSize)
+	}
+	if r.ipVersion != 4 && r.ipVersion != 6 {
+		return nil, fmt.Errorf("%w: unsupported ip version %d", ErrInvalidDatabase, r.ipVersion)
+	}
+
+	treeSize := r.nodeCount * r.recordSize / 4
+	if treeSize+dataSectionSeparator > uint(metaStart-len(metadataMarker)) {
+		return nil, fmt.Errorf("%w: search tree exceeds file", ErrInvalidDatabase)
+	}
+	r.data = buf[treeSize+dataSectionSeparator : metaStart-len(metadataMarker)]
+
+	// IPv4 addresses live under ::/96 in an IPv6 tree
+	if r.ipVersion == 6 {
+		node := uint(0)
+		for i := 0; i < 96 && node < r.nodeCount; i++ {
+			node = r.readRecord(node, 0)
+		}
+		r.ipv4Start = node
+	}
+
+	return r, nil
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of the country the address is
+// located in, falling back to the country it is registered to. It returns ""
+// when the database has no country for the address.
+func (r *Reader) Country(addr netip.Addr) (string, error) {
+	offset, ok, err := r.lookup(addr)
+	if err != nil || !ok {
+		return "", err
+	}
+
+	d := decoder{buf: r.data}
+	for _, field := range []string{"country", "registered_country"} {
+		value, err := d.lookupPath(offset, field, "iso_code")
+		if err != nil {
+			return "", err
+		}
+		if code, ok := value.(string); ok && code != "" {
+			return code, nil
+		}
+	}
+	return "", nil
+}
+
+// lookup walks the search tree and returns the data section offset of the
+// record for the address.
+func (r *Reader) lookup(addr netip.Addr) (uint, bool, error) {
+	addr = addr.Unmap()
+
+	var ip []byte
+	node := uint(0)
+	if addr.Is4() {
+		a := addr.As4()
+		ip = a[:]
+		if r.ipVersion == 6 {
+			node = r.ipv4Start
+		}
+	} else {
+		if r.ipVersion == 4 {
+			return 0, false, nil
+		}
+		a := addr.As16()
+		ip = a[:]
+	}
+
+	for i := 0; i < len(ip)*8 && node < r.nodeCount; i++ {
+		bit := uint(ip[i/8]>>(7-i%8)) & 1
+		node = r.readRecord(node, bit)
+	}
+
+	switch {
+	case node == r.nodeCount:
+		return 0, false, nil
+	case node > r.nodeCount:
+		offset := node - r.nodeCount - dataSectionSeparator
+		if offset >= uint(len(r.data)) {
+			return 0, false, ErrInvalidDatabase
+		}
+		return offset, true, nil
+	default:
+		return 0, false, ErrInvalidDatabase
+	}
+}
+
+// readRecord returns the left (bit 0) or right (bit 1) record of a node.
+func (r *Reader) readRecord(node, bit uint) uint {
+	switch r.recordSize {
+	case 24:
+		b := r.buf[node*6+bit*3:]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+	case 28:
+		b := r.buf[node*7:]
+		if bit == 0 {
+			return uint(b[3]&0xf0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0f)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		b := r.buf[node*8+bit*4:]
+		return uint(b[0])<<24 | uint(b[1])<<16 | uint(b[2])<<8 | uint(b[3])
+	}
+}
+
+func metadataUint(fields map[string]any, key string) uint {
+	switch v := fields[key].(type) {
+	case uint64:
+		return uint(v)
+	case int32:
+		return uint(v)
+	}
+	return 0
+}
+
+// decoder reads values from a data section. Pointers are offsets from the
+// start of buf.
+type decoder struct {
+	buf []byte
+}
+
+// maxDepth bounds nesting and pointer chains in corrupt files.
+const maxDepth = 32
+
+// lookupPath follows map keys from the value at offset and decodes the value
+// at the end of the path, or returns nil when a key is missing.
+func (d *decoder) lookupPath(offset uint, path ...string) (any, error) {
+	for depth := 0; ; depth++ {
+		if depth > maxDepth {
+			return nil, ErrInvalidDatabase
+		}
+		if len(path) == 0 {
+			value, _, err := d.decode(offset, 0)
+			return value, err
+		}
+
+		typ, size, next, err := d.control(offset)
+		if err != nil {
+			return nil, err
+		}
+		if typ == typePointer {
+			offset, _, err = d.pointer(size, next)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if typ != typeMap {
+			return nil, nil
+		}
+
+		found := false
+		for range size {
+			key, afterKey, err := d.decode(next, 0)
+			if err != nil {
+				return nil, err
+			}
+			if key == path[0] {
+				offset, path, found = afterKey, path[1:], true
+				break
+			}
+			if next, err = d.skip(afterKey, 0); err != nil {
+				return nil, err
+			}
+		}
+		if !found {
+			return nil, nil
+		}
+	}
+}
+
+// control reads a field's control byte(s) and returns its type, its size and
+// the offset of its payload.
+func (d *decoder) control(offset uint) (int, uint, uint, error) {
+	if offset >= uint(len(d.buf)) {
+		return 0, 0, 0, ErrInvalidDatabase
+	}
+	ctrl := d.buf[offset]
+	offset++
+
+	typ := int(ctrl >> 5)
+	if typ == typeExtended {
+		if offset >= uint(len(d.buf)) {
+			return 0, 0, 0, ErrInvalidDatabase
+		}
+		typ = 7 + int(d.buf[offset])
+		offset++
+	}
+
+	size := uint(ctrl & 0x1f)
+	if typ == typePointer {
+		// the size bits hold the pointer's own layout
+		return typ, size, offset, nil
+	}
+
+	switch size {
+	case 29, 30, 31:
+		n := size - 28
+		if offset+n > uint(len(d.buf)) {
+			return 0, 0, 0, ErrInvalidDatabase
+		}
+		extra := uint(0)
+		for _, b := range d.buf[offset : offset+n] {
+			extra = extra<<8 | uint(b)
+		}
+		size = [...]uint{29, 285, 65821}[n-1] + extra
+		offset += n
+	}
+	return typ, size, offset, nil
+}
+
+// pointer resolves a pointer field from its size bits and returns the offset
+// it points to and the offset after the pointer.
+func (d *decoder) pointer(sizeBits, offset uint) (uint, uint, error) {
+	n := (sizeBits>>3)&0x3 + 1
+	if offset+n > uint(len(d.buf)) {
+		return 0, 0, ErrInvalidDatabase
+	}
+
+	value := uint(0)
+	if n < 4 {
+		value = sizeBits & 0x7
+	}
+	for _, b := range d.buf[offset : offset+n] {
+		value = value<<8 | uint(b)
+	}
+	value += [...]uint{0, 2048, 526336, 0}[n-1]
+	return value, offset + n, nil
+}
+
+// decode returns the value at offset and the offset after it. Maps decode to
+// map[string]any, arrays to []any, unsigned integers to uint64 and uint128
+// to []byte.
+func (d *decoder) decode(offset uint, depth int) (any, uint, error) {
+	if depth > maxDepth {
+		return nil, 0, ErrInvalidDatabase
+	}
+
+	typ, size, offset, err := d.control(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch typ {
+	case typePointer:
+		target, next, err := d.pointer(size, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		value, _, err := d.decode(target, depth+1)
+		return value, next, err
+
+	case typeMap:
+		m := make(map[string]any, size)
+		for range size {
+			key, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			k, ok := key.(string)
+			if !ok {
+				return nil, 0, ErrInvalidDatabase
+			}
+			if m[k], offset, err = d.decode(next, depth+1); err != nil {
+				return nil, 0, err
+			}
+		}
+		return m, offset, nil
+
+	case typeArray:
+		a := make([]any, 0, size)
+		for range size {
+			value, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			a, offset = append(a, value), next
+		}
+		return a, offset, nil
+
+	case typeBool:
+		return size != 0, offset, nil
+	}
+
+	if offset+size > uint(len(d.buf)) {
+		return nil, 0, ErrInvalidDatabase
+	}
+	payload := d.buf[offset : offset+size]
+	offset += size
+
+	switch typ {
+	case typeString:
+		return string(payload), offset, nil
+	case typeBytes, typeUint128:
+		return bytes.Clone(payload), offset, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, ErrInvalidDatabase
+		}
+		return math.Float64frombits(uint64(beUint(payload))), offset, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, ErrInvalidDatabase
+		}
+		return math.Float32frombits(uint32(beUint(payload))), offset, nil
+	case typeUint16, typeUint32, typeUint64:
+		if size > 8 {
+			return nil, 0, ErrInvalidDatabase
+		}
+		return beUint(payload), offset, nil
+	case typeInt32:
+		if size > 4 {
+			return nil, 0, ErrInvalidDatabase
+		}
+		return int32(uint32(beUint(payload))), offset, nil
+	default:
+		return nil, 0, fmt.Errorf("%w: unexpected field type %d", ErrInvalidDatabase, typ)
+	}
+}
+
+// skip returns the offset after the value at offset without decoding it.
+// Pointers are skipped, not followed.
+func (d *decoder) skip(offset uint, depth int) (uint, error) {
+	if depth > maxDepth {
+		return 0, ErrInvalidDatabase
+	}
+
+	typ, size, offset, err := d.control(offset)
+	if err != nil {
+		return 0, err
+	}
+
+	switch typ {
+	case typePointer:
+		_, next, err := d.pointer(size, offset)
+		return next, err
+	case typeMap, typeArray:
+		count := size
+		if typ == typeMap {
+			count *= 2
+		}
+		for range count {
+			if offset, err = d.skip(offset, depth+1); err != nil {
+				return 0, err
+			}
+		}
+		return offset, nil
+	case typeBool:
+		return offset, nil
+	}
+
+	if offset+size > uint(len(d.buf)) {
+		return 0, ErrInvalidDatabase
+	}
+	return offset + size, nil
+}
+
+func beUint(b []byte) uint64 {
+	var v uint64
+	for _, x := range b {
+		v = v<<8 | uint64(x)
+	}
+	return v
+}
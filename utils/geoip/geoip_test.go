@@ -0,0 +1,227 @@
+package geoip
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"testing"
+)
+
+// trieNode is a node of the search tree being written. A record either
+// leads to a child node, holds a data section offset, or is empty (-1).
+type trieNode struct {
+	children [2]*trieNode
+	records  [2]int
+}
+
+func newTrieNode() *trieNode {
+	return &trieNode{records: [2]int{-1, -1}}
+}
+
+// insert maps an IPv4 or IPv6 prefix to a data section offset.
+func (n *trieNode) insert(prefix netip.Prefix, offset int) {
+	ip := prefix.Addr().As16()
+	bits := prefix.Bits()
+	if prefix.Addr().Is4() {
+		// IPv4 lives under ::/96, not under the ::ffff:0:0/96 of As16
+		var v4 [16]byte
+		a := prefix.Addr().As4()
+		copy(v4[12:], a[:])
+		ip, bits = v4, bits+96
+	}
+	for i := range bits {
+		bit := ip[i/8] >> (7 - i%8) & 1
+		if i == bits-1 {
+			n.records[bit] = offset
+			return
+		}
+		if n.children[bit] == nil {
+			n.children[bit] = newTrieNode()
+		}
+		n = n.children[bit]
+	}
+}
+
+// buildDatabase writes an IPv6 MMDB with the given record size, mapping each
+// prefix to the data section offset of its record.
+func buildDatabase(t *testing.T, recordSize int, data []byte, prefixes map[string]int) []byte {
+	t.Helper()
+
+	root := newTrieNode()
+	for prefix, offset := range prefixes {
+		root.insert(netip.MustParsePrefix(prefix), offset)
+	}
+
+	var nodes []*trieNode
+	index := map[*trieNode]int{}
+	for queue := []*trieNode{root}; len(queue) > 0; queue = queue[1:] {
+		index[queue[0]] = len(nodes)
+		nodes = append(nodes, queue[0])
+		for _, child := range queue[0].children {
+			if child != nil {
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	nodeCount := len(nodes)
+	var buf []byte
+	for _, n := range nodes {
+		var records [2]uint32
+		for bit := range 2 {
+			switch {
+			case n.children[bit] != nil:
+				records[bit] = uint32(index[n.children[bit]])
+			case n.records[bit] >= 0:
+				records[bit] = uint32(nodeCount + dataSectionSeparator + n.records[bit])
+			default:
+				records[bit] = uint32(nodeCount)
+			}
+		}
+
+		l, r := records[0], records[1]
+		switch recordSize {
+		case 24:
+			buf = append(buf, byte(l>>16), byte(l>>8), byte(l), byte(r>>16), byte(r>>8), byte(r))
+		case 28:
+			buf = append(buf, byte(l>>16), byte(l>>8), byte(l), byte(l>>20)&0xf0|byte(r>>24)&0x0f, byte(r>>16), byte(r>>8), byte(r))
+		case 32:
+			buf = append(buf, byte(l>>24), byte(l>>16), byte(l>>8), byte(l), byte(r>>24), byte(r>>16), byte(r>>8), byte(r))
+		}
+	}
+
+	buf = append(buf, make([]byte, dataSectionSeparator)...)
+	buf = append(buf, data...)
+	buf = append(buf, metadataMarker...)
+	buf = append(buf, mapOf(4)...)
+	buf = append(buf, str("node_count")...)
+	buf = append(buf, 0xc4, byte(nodeCount>>24), byte(nodeCount>>16), byte(nodeCount>>8), byte(nodeCount))
+	buf = append(buf, str("record_size")...)
+	buf = append(buf, 0xa1, byte(recordSize))
+	buf = append(buf, str("ip_version")...)
+	buf = append(buf, 0xa1, 6)
+	buf = append(buf, str("database_type")...)
+	buf = append(buf, str("Test-Country")...)
+	return buf
+}
+
+func str(s string) []byte {
+	return append([]byte{0x40 | byte(len(s))}, s...)
+}
+
+func mapOf(size int) []byte {
+	return []byte{0xe0 | byte(size)}
+}
+
+func pointer(offset int) []byte {
+	return []byte{0x20 | byte(offset>>8)&0x7, byte(offset)}
+}
+
+func TestCountry(t *testing.T) {
+	// offset 0: {"iso_code": "DE"}, shared through pointers
+	var data []byte
+	data = append(data, mapOf(1)...)
+	data = append(data, str("iso_code")...)
+	data = append(data, str("DE")...)
+
+	// {"country": -> 0}: the value is a pointer
+	germany := len(data)
+	data = append(data, mapOf(1)...)
+	data = append(data, str("country")...)
+	data = append(data, pointer(0)...)
+
+	// {"registered_country": {"iso_code": "FR"}}: no country field
+	france := len(data)
+	data = append(data, mapOf(1)...)
+	data = append(data, str("registered_country")...)
+	data = append(data, mapOf(1)...)
+	data = append(data, pointer(1)...) // the "iso_code" key of offset 0
+	data = append(data, str("FR")...)
+
+	// {"continent": "EU", "country": {"iso_code": "GB"}}: the record itself
+	// is reached through a pointer, and "continent" must be skipped
+	britainMap := len(data)
+	data = append(data, mapOf(2)...)
+	data = append(data, str("continent")...)
+	data = append(data, str("EU")...)
+	data = append(data, str("country")...)
+	data = append(data, mapOf(1)...)
+	data = append(data, str("iso_code")...)
+	data = append(data, str("GB")...)
+	britain := len(data)
+	data = append(data, pointer(britainMap)...)
+
+	// {"city": "Nowhere"}: a record without any country
+	nowhere := len(data)
+	data = append(data, mapOf(1)...)
+	data = append(data, str("city")...)
+	data = append(data, str("Nowhere")...)
+
+	prefixes := map[string]int{
+		"1.2.3.0/24":     germany,
+		"81.2.69.128/26": britain,
+		"2001:db8::/32":  france,
+		"10.0.0.0/8":     nowhere,
+	}
+
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"1.2.3.4", "DE"},
+		{"::ffff:1.2.3.4", "DE"},
+		{"1.2.3.255", "DE"},
+		{"81.2.69.160", "GB"},
+		{"2001:db8::1", "FR"},
+		{"10.1.2.3", ""},
+		{"1.2.4.1", ""},
+		{"8.8.8.8", ""},
+		{"2001:db9::1", ""},
+		{"::1", ""},
+	}
+
+	for _, recordSize := range []int{24, 28, 32} {
+		t.Run(fmt.Sprintf("record size %d", recordSize), func(t *testing.T) {
+			r, err := FromBytes(buildDatabase(t, recordSize, data, prefixes))
+			if err != nil {
+				t.Fatalf("FromBytes: %v", err)
+			}
+			if r.DatabaseType != "Test-Country" {
+				t.Errorf("DatabaseType = %q, want Test-Country", r.DatabaseType)
+			}
+
+			for _, tt := range tests {
+				got, err := r.Country(netip.MustParseAddr(tt.addr))
+				if err != nil {
+					t.Errorf("Country(%s): %v", tt.addr, err)
+					continue
+				}
+				if got != tt.want {
+					t.Errorf("Country(%s) = %q, want %q", tt.addr, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFromBytesInvalid(t *testing.T) {
+	metadata := func(parts ...[]byte) []byte {
+		buf := append([]byte{}, metadataMarker...)
+		for _, p := range parts {
+			buf = append(buf, p...)
+		}
+		return buf
+	}
+
+	tests := map[string][]byte{
+		"no metadata":      []byte("not a database"),
+		"empty metadata":   metadata(),
+		"metadata not map": metadata(str("x")),
+		"bad record size":  metadata(mapOf(1), str("record_size"), []byte{0xa1, 20}),
+	}
+	for name, buf := range tests {
+		if _, err := FromBytes(buf); !errors.Is(err, ErrInvalidDatabase) {
+			t.Errorf("%s: error = %v, want ErrInvalidDatabase", name, err)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package helpers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// HashIP returns a salted SHA-256 of the client IP so clicks can be told
+// apart without storing the address itself.
+func HashIP(ip, salt string) string {
+	sum := sha256.Sum256([]byte(salt + ip))
+	return hex.EncodeToString(sum[:])
+}
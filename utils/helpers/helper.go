@@ -2,6 +2,7 @@ package helpers
 
 import (
 	"net/url"
+	"strings"
 )
 
 func IsValidURL(rawURL string) bool {
@@ -21,3 +22,13 @@ func IsValidURL(rawURL string) bool {
 
 	return true
 }
+
+// HostOf returns the lowercased host of a URL without its port, or an empty
+// string when the URL cannot be parsed.
+func HostOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
@@ -0,0 +1,43 @@
+package helpers
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PreferredLanguage returns the tag with the highest quality in an
+// Accept-Language header, lowercased, or "" when the header names none.
+// Ties keep the order of the header and "*" is ignored.
+func PreferredLanguage(header string) string {
+	best, bestQ := "", 0.0
+
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		if q > bestQ {
+			best, bestQ = tag, q
+		}
+	}
+	return best
+}
+
+// MatchLanguage reports whether the visitor's language tag falls under the
+// wanted tag: "pt" matches "pt" and "pt-br", while "pt-br" only matches
+// "pt-br". Both are compared case-insensitively.
+func MatchLanguage(wanted, tag string) bool {
+	wanted, tag = strings.ToLower(wanted), strings.ToLower(tag)
+	return tag == wanted || strings.HasPrefix(tag, wanted+"-")
+}
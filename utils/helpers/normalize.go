@@ -0,0 +1,167 @@
+package helpers
+
+import (
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// NormalizeOptions switches the individual steps of NormalizeURL on or off.
+type NormalizeOptions struct {
+	LowercaseHost     bool // lowercase the scheme and host
+	RemoveDefaultPort bool // drop :80 on http and :443 on https
+	Punycode          bool // convert internationalized hosts to punycode
+	CleanPath         bool // resolve "." and ".." segments
+	SortQuery         bool // order query parameters by name
+	StripTracking     bool // drop the parameters listed in TrackingParams
+
+	// TrackingParams are matched case-insensitively; a trailing "*" matches
+	// any parameter with that prefix, e.g. "utm_*".
+	TrackingParams []string
+}
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// NormalizeURL rewrites an absolute URL into a canonical form so equivalent
+// URLs compare equal. Steps that are switched off leave that part untouched.
+func NormalizeURL(rawURL string, opts NormalizeOptions) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	host, port := u.Hostname(), u.Port()
+
+	if opts.LowercaseHost {
+		u.Scheme = strings.ToLower(u.Scheme)
+		host = strings.ToLower(host)
+	}
+	if opts.Punycode {
+		if host, err = idna.Punycode.ToASCII(host); err != nil {
+			return "", err
+		}
+	}
+	if opts.RemoveDefaultPort && port == defaultPorts[strings.ToLower(u.Scheme)] {
+		port = ""
+	}
+
+	if port != "" {
+		u.Host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		u.Host = "[" + host + "]" // IPv6 literal
+	} else {
+		u.Host = host
+	}
+
+	if opts.CleanPath {
+		escaped := removeDotSegments(u.EscapedPath())
+		if escaped == "" && u.Host != "" {
+			escaped = "/"
+		}
+		if u.Path, err = url.PathUnescape(escaped); err != nil {
+			return "", err
+		}
+		u.RawPath = escaped
+	}
+
+	if opts.SortQuery || opts.StripTracking {
+		u.RawQuery = normalizeQuery(u.RawQuery, opts)
+		if u.RawQuery == "" {
+			u.ForceQuery = false
+		}
+	}
+
+	return u.String(), nil
+}
+
+// removeDotSegments implements section 5.2.4 of RFC 3986.
+func removeDotSegments(path string) string {
+	var output []string
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		switch segment {
+		case ".":
+			if last {
+				output = append(output, "")
+			}
+		case "..":
+			if len(output) > 1 {
+				output = output[:len(output)-1]
+			}
+			if last {
+				output = append(output, "")
+			}
+		default:
+			output = append(output, segment)
+		}
+	}
+
+	result := strings.Join(output, "/")
+	if strings.HasPrefix(path, "/") && !strings.HasPrefix(result, "/") {
+		result = "/" + result
+	}
+	return result
+}
+
+// normalizeQuery sorts and filters the query string without re-encoding
+// it, so parameters keep their original escaping. Repeated names keep
+// their relative order because it can be significant.
+func normalizeQuery(rawQuery string, opts NormalizeOptions) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	type param struct {
+		name string
+		raw  string
+	}
+
+	var params []param
+	for _, raw := range strings.Split(rawQuery, "&") {
+		if raw == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(raw, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if opts.StripTracking && isTrackingParam(name, opts.TrackingParams) {
+			continue
+		}
+		params = append(params, param{name: name, raw: raw})
+	}
+
+	if opts.SortQuery {
+		sort.SliceStable(params, func(i, j int) bool {
+			return params[i].name < params[j].name
+		})
+	}
+
+	raws := make([]string, len(params))
+	for i, p := range params {
+		raws[i] = p.raw
+	}
+	return strings.Join(raws, "&")
+}
+
+func isTrackingParam(name string, tracking []string) bool {
+	name = strings.ToLower(name)
+	for _, t := range tracking {
+		t = strings.ToLower(t)
+		if prefix, ok := strings.CutSuffix(t, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package helpers
+
+import "strings"
+
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+)
+
+type UserAgent struct {
+	Browser string
+	OS      string
+	Device  string
+}
+
+// ParseUserAgent classifies a User-Agent header into browser, operating
+// system and device family. It only knows the common families; anything
+// else is reported as "Other".
+func ParseUserAgent(ua string) UserAgent {
+	lower := strings.ToLower(ua)
+
+	result := UserAgent{
+		Browser: parseBrowser(ua, lower),
+		OS:      parseOS(ua),
+	}
+
+	switch {
+	case result.Browser == "Bot":
+		result.Device = DeviceBot
+	case strings.Contains(ua, "iPad") || strings.Contains(lower, "tablet") ||
+		(strings.Contains(ua, "Android") && !strings.Contains(ua, "Mobile")):
+		result.Device = DeviceTablet
+	case strings.Contains(ua, "Mobi") || strings.Contains(ua, "iPhone") || strings.Contains(ua, "iPod"):
+		result.Device = DeviceMobile
+	default:
+		result.Device = DeviceDesktop
+	}
+
+	return result
+}
+
+func parseBrowser(ua, lower string) string {
+	switch {
+	case ua == "":
+		return "Other"
+	case strings.Contains(lower, "bot") || strings.Contains(lower, "crawler") || strings.Contains(lower, "spider"):
+		return "Bot"
+	case strings.Contains(ua, "Edg/") || strings.Contains(ua, "EdgA/") || strings.Contains(ua, "EdgiOS/"):
+		return "Edge"
+	case strings.Contains(ua, "OPR/") || strings.Contains(ua, "Opera"):
+		return "Opera"
+	case strings.Contains(ua, "SamsungBrowser/"):
+		return "Samsung Internet"
+	case strings.Contains(ua, "Firefox/") || strings.Contains(ua, "FxiOS/"):
+		return "Firefox"
+	case strings.Contains(ua, "Chrome/") || strings.Contains(ua, "CriOS/"):
+		return "Chrome"
+	case strings.Contains(ua, "Safari/"):
+		return "Safari"
+	case strings.Contains(ua, "MSIE") || strings.Contains(ua, "Trident/"):
+		return "Internet Explorer"
+	default:
+		return "Other"
+	}
+}
+
+func parseOS(ua string) string {
+	switch {
+	case strings.Contains(ua, "Windows"):
+		return "Windows"
+	case strings.Contains(ua, "iPhone") || strings.Contains(ua, "iPad") || strings.Contains(ua, "iPod"):
+		return "iOS"
+	case strings.Contains(ua, "Android"):
+		return "Android"
+	case strings.Contains(ua, "CrOS"):
+		return "ChromeOS"
+	case strings.Contains(ua, "Macintosh") || strings.Contains(ua, "Mac OS X"):
+		return "macOS"
+	case strings.Contains(ua, "Linux"):
+		return "Linux"
+	default:
+		return "Other"
+	}
+}
@@ -0,0 +1,240 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestReedSolomonRemainder(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		ecc  []byte
+	}{
+		{
+			// the worked example of annex I: "01234567" at version 1-M
+			name: "01234567",
+			data: []byte{0x10, 0x20, 0x0C, 0x56, 0x61, 0x80, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11},
+			ecc:  []byte{0xA5, 0x24, 0xD4, 0xC1, 0xED, 0x36, 0xC7, 0x87, 0x2C, 0x55},
+		},
+		{
+			name: "HELLO WORLD",
+			data: []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17},
+			ecc:  []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reedSolomonRemainder(tt.data, reedSolomonDivisor(len(tt.ecc)))
+			if !bytes.Equal(got, tt.ecc) {
+				t.Errorf("remainder = %v, want %v", got, tt.ecc)
+			}
+		})
+	}
+}
+
+func TestEncodeVersion(t *testing.T) {
+	// byte mode capacities from table 7 of the specification
+	tests := []struct {
+		level    Level
+		capacity int
+		version  int
+	}{
+		{Low, 17, 1},
+		{Medium, 14, 1},
+		{Quartile, 11, 1},
+		{High, 7, 1},
+		{Medium, 26, 2},
+		{Medium, 213, 10},
+		{Low, 2953, 40},
+	}
+
+	for _, tt := range tests {
+		c, err := Encode(make([]byte, tt.capacity), tt.level)
+		if err != nil {
+			t.Fatalf("Encode(%d bytes, %d): %v", tt.capacity, tt.level, err)
+		}
+		if c.Version != tt.version || c.Size != tt.version*4+17 {
+			t.Errorf("Encode(%d bytes, %d) = version %d size %d, want version %d",
+				tt.capacity, tt.level, c.Version, c.Size, tt.version)
+		}
+
+		if tt.version == maxVersion {
+			if _, err := Encode(make([]byte, tt.capacity+1), tt.level); !errors.Is(err, ErrDataTooLong) {
+				t.Errorf("Encode(%d bytes, %d) error = %v, want ErrDataTooLong", tt.capacity+1, tt.level, err)
+			}
+			continue
+		}
+		c, err = Encode(make([]byte, tt.capacity+1), tt.level)
+		if err != nil {
+			t.Fatalf("Encode(%d bytes, %d): %v", tt.capacity+1, tt.level, err)
+		}
+		if c.Version != tt.version+1 {
+			t.Errorf("Encode(%d bytes, %d) = version %d, want %d", tt.capacity+1, tt.level, c.Version, tt.version+1)
+		}
+	}
+}
+
+// mediumFormats is the format information for level M with masks 0 to 7,
+// after the 0x5412 mask, from table C.1 of the specification.
+var mediumFormats = [8]int{0x5412, 0x5125, 0x5E7C, 0x5B4B, 0x45F9, 0x40CE, 0x4F97, 0x4AA0}
+
+// TestEncodeMatrix reads a version 1 symbol back module by module, the way
+// a scanner would, and checks it carries the payload.
+func TestEncodeMatrix(t *testing.T) {
+	payload := []byte("hello, world!")
+	c, err := Encode(payload, Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Version != 1 || c.Size != 21 {
+		t.Fatalf("version %d size %d, want version 1 size 21", c.Version, c.Size)
+	}
+
+	for _, corner := range [][2]int{{0, 0}, {c.Size - 7, 0}, {0, c.Size - 7}} {
+		for dy := range 7 {
+			for dx := range 7 {
+				ring := max(abs(dx-3), abs(dy-3))
+				if want := ring != 2; c.Dark(corner[0]+dx, corner[1]+dy) != want {
+					t.Fatalf("finder at %v: module (%d, %d) = %v, want %v", corner, dx, dy, !want, want)
+				}
+			}
+		}
+	}
+	for i := 8; i < c.Size-8; i++ {
+		if c.Dark(i, 6) != (i%2 == 0) || c.Dark(6, i) != (i%2 == 0) {
+			t.Fatalf("timing pattern broken at %d", i)
+		}
+	}
+	if !c.Dark(8, 4*c.Version+9) {
+		t.Error("dark module is light")
+	}
+
+	var first, second int
+	for i := 0; i <= 5; i++ {
+		first |= b2i(c.Dark(8, i)) << i
+	}
+	first |= b2i(c.Dark(8, 7))<<6 | b2i(c.Dark(8, 8))<<7 | b2i(c.Dark(7, 8))<<8
+	for i := 9; i < 15; i++ {
+		first |= b2i(c.Dark(14-i, 8)) << i
+	}
+	for i := range 8 {
+		second |= b2i(c.Dark(c.Size-1-i, 8)) << i
+	}
+	for i := 8; i < 15; i++ {
+		second |= b2i(c.Dark(8, c.Size-15+i)) << i
+	}
+	if first != second {
+		t.Fatalf("format copies differ: %015b and %015b", first, second)
+	}
+	mask := -1
+	for m, format := range mediumFormats {
+		if format == first {
+			mask = m
+		}
+	}
+	if mask < 0 {
+		t.Fatalf("format %015b is not a level M format", first)
+	}
+
+	reserved := func(x, y int) bool {
+		return x == 6 || y == 6 || (x < 9 && y < 9) || (x >= c.Size-8 && y < 9) || (x < 9 && y >= c.Size-8)
+	}
+	var codewords []byte
+	var cur byte
+	var n int
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := range c.Size {
+			y := vert
+			if (right+1)&2 == 0 {
+				y = c.Size - 1 - vert
+			}
+			for x := right; x > right-2; x-- {
+				if reserved(x, y) {
+					continue
+				}
+				dark := c.Dark(x, y) != masked(mask, x, y)
+				cur = cur<<1 | byte(b2i(dark))
+				if n++; n%8 == 0 {
+					codewords = append(codewords, cur)
+					cur = 0
+				}
+			}
+		}
+	}
+	if len(codewords) != 26 {
+		t.Fatalf("read %d codewords, want 26", len(codewords))
+	}
+
+	data, ecc := codewords[:16], codewords[16:]
+	if got := reedSolomonRemainder(data, reedSolomonDivisor(10)); !bytes.Equal(got, ecc) {
+		t.Errorf("error correction = %v, want %v", ecc, got)
+	}
+
+	want := []byte{0x40 | byte(len(payload))>>4, byte(len(payload)) << 4}
+	for _, b := range payload {
+		want[len(want)-1] |= b >> 4
+		want = append(want, b<<4)
+	}
+	for pad := byte(0xEC); len(want) < 16; pad ^= 0xEC ^ 0x11 {
+		want = append(want, pad)
+	}
+	if !bytes.Equal(data, want) {
+		t.Errorf("data codewords = %x, want %x", data, want)
+	}
+}
+
+func TestEncodeVersionInformation(t *testing.T) {
+	c, err := Encode(make([]byte, 122), Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Version != 7 {
+		t.Fatalf("version %d, want 7", c.Version)
+	}
+
+	// version 7 is 000111 110010010100 in table D.1 of the specification
+	const want = 0x07C94
+	var bottomLeft, topRight int
+	for i := range 18 {
+		a, b := c.Size-11+i%3, i/3
+		bottomLeft |= b2i(c.Dark(b, a)) << i
+		topRight |= b2i(c.Dark(a, b)) << i
+	}
+	if bottomLeft != want || topRight != want {
+		t.Errorf("version information = %018b and %018b, want %018b", bottomLeft, topRight, want)
+	}
+}
+
+func masked(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
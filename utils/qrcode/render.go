@@ -0,0 +1,113 @@
+package qrcode
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Options control how a code is rendered.
+type Options struct {
+	// Size is the width and height of the image in pixels. Modules are
+	// scaled by a whole number of pixels and any remainder widens the
+	// quiet zone, so the image never renders blurry.
+	Size int
+	// Margin is the quiet zone around the symbol, in modules.
+	Margin     int
+	Foreground color.NRGBA
+	Background color.NRGBA
+}
+
+// PNG writes the code as a two-colour PNG.
+func (c *Code) PNG(w io.Writer, opts Options) error {
+	total := c.Size + opts.Margin*2
+	size := max(opts.Size, total)
+	scale := size / total
+	offset := (size-scale*total)/2 + opts.Margin*scale
+
+	img := image.NewPaletted(image.Rect(0, 0, size, size), color.Palette{opts.Background, opts.Foreground})
+	for y := range c.Size {
+		for x := range c.Size {
+			if !c.modules[y][x] {
+				continue
+			}
+			for py := range scale {
+				row := img.Pix[(offset+y*scale+py)*img.Stride:]
+				for px := range scale {
+					row[offset+x*scale+px] = 1
+				}
+			}
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+// SVG writes the code as an SVG drawing in module units, with each run of
+// dark modules in a row drawn as a single rectangle of the path.
+func (c *Code) SVG(w io.Writer, opts Options) error {
+	total := c.Size + opts.Margin*2
+	size := max(opts.Size, total)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" version="1.1" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+"\n",
+		size, size, total, total)
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%" fill="%s"%s/>`+"\n", hexColor(opts.Background), opacity(opts.Background))
+
+	bw.WriteString(`<path d="`)
+	for y := range c.Size {
+		for x := 0; x < c.Size; x++ {
+			if !c.modules[y][x] {
+				continue
+			}
+			run := 1
+			for x+run < c.Size && c.modules[y][x+run] {
+				run++
+			}
+			fmt.Fprintf(bw, "M%d,%dh%dv1h-%dz", x+opts.Margin, y+opts.Margin, run, run)
+			x += run - 1
+		}
+	}
+	fmt.Fprintf(bw, `" fill="%s"%s/>`+"\n", hexColor(opts.Foreground), opacity(opts.Foreground))
+	bw.WriteString("</svg>\n")
+
+	return bw.Flush()
+}
+
+// ParseColor reads a colour written as RGB, RRGGBB or RRGGBBAA hex digits,
+// with or without a leading "#".
+func ParseColor(s string) (color.NRGBA, bool) {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) == 6 {
+		s += "ff"
+	}
+	if len(s) != 8 {
+		return color.NRGBA{}, false
+	}
+
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, false
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, true
+}
+
+func hexColor(c color.NRGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func opacity(c color.NRGBA) string {
+	if c.A == 0xff {
+		return ""
+	}
+	return fmt.Sprintf(` fill-opacity="%.3f"`, float64(c.A)/0xff)
+}
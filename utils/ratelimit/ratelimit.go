@@ -0,0 +1,69 @@
+// Package ratelimit implements a GCRA (generic cell rate algorithm) limiter
+// whose state lives in Redis, so every replica enforces the same quota.
+package ratelimit
+
+import (
+	stdctx "context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Each key stores its theoretical arrival time (TAT) in microseconds. A
+// request is allowed while the TAT stays within one period of now, which
+// lets a client burst up to the full quota and then refills it evenly.
+// Redis' own clock is used so replicas never disagree about "now".
+var gcra = redis.NewScript(`
+local emission = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+
+local tat = tonumber(redis.call('GET', KEYS[1]))
+if not tat or tat < now then
+	tat = now
+end
+
+local new_tat = tat + emission
+local diff = new_tat - now
+if diff > period then
+	return {0, 0, diff - period, tat - now}
+end
+
+redis.call('SET', KEYS[1], string.format('%d', new_tat), 'PX', math.ceil(diff / 1000))
+return {1, math.floor((period - diff) / emission), 0, diff}
+`)
+
+// Result describes the outcome of a single request against a quota.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+
+	// RetryAfter is how long a rejected client should wait, and ResetAfter
+	// how long until the full quota is available again.
+	RetryAfter time.Duration
+	ResetAfter time.Duration
+}
+
+// Allow counts one request against key, which may make requests per period.
+func Allow(ctx stdctx.Context, rdb *redis.Client, key string, requests int, period time.Duration) (*Result, error) {
+	emission := period.Microseconds() / int64(requests)
+	if emission <= 0 {
+		emission = 1
+	}
+
+	values, err := gcra.Run(ctx, rdb, []string{key}, emission, period.Microseconds()).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Allowed:    values[0] == 1,
+		Limit:      requests,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Microsecond,
+		ResetAfter: time.Duration(values[3]) * time.Microsecond,
+	}, nil
+}